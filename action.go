@@ -0,0 +1,344 @@
+package datastar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentType is the type of content a backend action sends to the server.
+type ContentType string
+
+const (
+	ContentTypeJSON ContentType = "json" // Send all signals in a JSON request (the default)
+	ContentTypeForm ContentType = "form" // Send the closest form's values, as if submitted
+)
+
+// RequestCancellation controls how a backend action cancels in-flight requests from the same element.
+type RequestCancellation string
+
+const (
+	RequestCancellationAuto     RequestCancellation = "auto"     // Cancel the previous request when a new one is made (the default)
+	RequestCancellationCleanup  RequestCancellation = "cleanup"  // Also cancel the request when the element is removed
+	RequestCancellationDisabled RequestCancellation = "disabled" // Allow concurrent requests
+)
+
+// Retry controls when a backend action retries a failed request.
+type Retry string
+
+const (
+	RetryAuto   Retry = "auto"   // Retry on network errors only (the default)
+	RetryError  Retry = "error"  // Retry on network errors and 4xx/5xx responses
+	RetryAlways Retry = "always" // Retry on everything except 204 and redirects
+	RetryNever  Retry = "never"  // Never retry
+)
+
+// ActionOption configures a backend action such as [Get] or [Post].
+type ActionOption interface {
+	applyAction(*actionOptions)
+}
+
+type actionOptions struct {
+	contentType         ContentType
+	filterSignals       Filter
+	selector            string
+	headers             []string
+	openWhenHidden      *bool
+	payload             string
+	requestCancellation RequestCancellation
+	retry               Retry
+	retryInterval       *time.Duration
+	retryScaler         *float64
+	retryMaxWait        *time.Duration
+	retryMaxCount       *int
+}
+
+type actionOption func(*actionOptions)
+
+func (o actionOption) applyAction(opts *actionOptions) {
+	o(opts)
+}
+
+// SelectorOption sets a CSS selector.
+type SelectorOption string
+
+// applyAction sets the form to send when used with [ContentTypeForm].
+func (o SelectorOption) applyAction(opts *actionOptions) {
+	opts.selector = string(o)
+}
+
+// WithSelector sets a CSS selector.
+// For backend actions with [ContentTypeForm], it selects the form to send, instead of the closest one.
+// For [SSE.PatchElements], it selects the elements to patch, instead of matching them by ID.
+func WithSelector(selector string) SelectorOption {
+	return SelectorOption(selector)
+}
+
+// WithContentType sets the type of content to send. Defaults to [ContentTypeJSON].
+func WithContentType(contentType ContentType) ActionOption {
+	return actionOption(func(opts *actionOptions) {
+		opts.contentType = contentType
+	})
+}
+
+// WithFilterSignals filters which signals to send using regular expressions.
+// By default, all signals except those beginning with an underscore are sent.
+func WithFilterSignals(filter Filter) ActionOption {
+	return actionOption(func(opts *actionOptions) {
+		opts.filterSignals = filter
+	})
+}
+
+// WithHeader adds a header to the request. It can be given multiple times.
+func WithHeader(name, value string) ActionOption {
+	return actionOption(func(opts *actionOptions) {
+		opts.headers = append(opts.headers, name, value)
+	})
+}
+
+// WithOpenWhenHidden sets whether to keep the connection open when the page is hidden.
+// Defaults to false for [Get] and true for other actions.
+func WithOpenWhenHidden(open bool) ActionOption {
+	return actionOption(func(opts *actionOptions) {
+		opts.openWhenHidden = &open
+	})
+}
+
+// WithPayload sets an expression to send as the request payload instead of the signals.
+func WithPayload(expression string) ActionOption {
+	return actionOption(func(opts *actionOptions) {
+		opts.payload = expression
+	})
+}
+
+// WithRequestCancellation sets how in-flight requests are cancelled. Defaults to [RequestCancellationAuto].
+func WithRequestCancellation(cancellation RequestCancellation) ActionOption {
+	return actionOption(func(opts *actionOptions) {
+		opts.requestCancellation = cancellation
+	})
+}
+
+// WithRetry sets when failed requests are retried. Defaults to [RetryAuto].
+func WithRetry(retry Retry) ActionOption {
+	return actionOption(func(opts *actionOptions) {
+		opts.retry = retry
+	})
+}
+
+// WithRetryInterval sets the initial interval between retries, rounded to the nearest millisecond.
+// Defaults to one second. Panics if the interval is negative.
+func WithRetryInterval(d time.Duration) ActionOption {
+	if d < 0 {
+		panic(fmt.Sprintf("retry interval must not be negative, but is: %v", d))
+	}
+	return actionOption(func(opts *actionOptions) {
+		opts.retryInterval = &d
+	})
+}
+
+// WithRetryScaler sets the factor the retry interval is multiplied with after each retry. Defaults to 2.
+func WithRetryScaler(scaler float64) ActionOption {
+	return actionOption(func(opts *actionOptions) {
+		opts.retryScaler = &scaler
+	})
+}
+
+// WithRetryMaxWait sets the maximum interval between retries, rounded to the nearest millisecond.
+// Defaults to 30 seconds. Panics if the duration is negative.
+func WithRetryMaxWait(d time.Duration) ActionOption {
+	if d < 0 {
+		panic(fmt.Sprintf("retry max wait must not be negative, but is: %v", d))
+	}
+	return actionOption(func(opts *actionOptions) {
+		opts.retryMaxWait = &d
+	})
+}
+
+// WithRetryMaxCount sets the maximum number of retries. Defaults to 10.
+func WithRetryMaxCount(count int) ActionOption {
+	return actionOption(func(opts *actionOptions) {
+		opts.retryMaxCount = &count
+	})
+}
+
+// Get sends a GET request to the backend. The response must contain zero or more Datastar SSE events.
+//
+// <button data-on:click="@get('/endpoint')"></button>
+//
+// The expression can be used in any attribute that takes an expression, such as [On], [Init], [OnInterval], and [Effect].
+//
+// See https://data-star.dev/reference/actions#get
+func Get(url string, opts ...ActionOption) string {
+	return action("get", url, opts)
+}
+
+// Post sends a POST request to the backend. Works the same way as [Get].
+//
+// <button data-on:click="@post('/endpoint')"></button>
+//
+// See https://data-star.dev/reference/actions#post
+func Post(url string, opts ...ActionOption) string {
+	return action("post", url, opts)
+}
+
+// Put sends a PUT request to the backend. Works the same way as [Get].
+//
+// <button data-on:click="@put('/endpoint')"></button>
+//
+// See https://data-star.dev/reference/actions#put
+func Put(url string, opts ...ActionOption) string {
+	return action("put", url, opts)
+}
+
+// Patch sends a PATCH request to the backend. Works the same way as [Get].
+//
+// <button data-on:click="@patch('/endpoint')"></button>
+//
+// See https://data-star.dev/reference/actions#patch
+func Patch(url string, opts ...ActionOption) string {
+	return action("patch", url, opts)
+}
+
+// Delete sends a DELETE request to the backend. Works the same way as [Get].
+//
+// <button data-on:click="@delete('/endpoint')"></button>
+//
+// See https://data-star.dev/reference/actions#delete
+func Delete(url string, opts ...ActionOption) string {
+	return action("delete", url, opts)
+}
+
+// Peek evaluates the expression without subscribing to changes in the signals it reads.
+//
+// <div data-text="$foo + @peek(() => $bar)"></div>
+//
+// See https://data-star.dev/reference/actions#peek
+func Peek(expression string) string {
+	return callAction("peek", "() => "+expression)
+}
+
+// SetAll sets the value of all signals matching the filter, or all signals if the filter is empty.
+// The value is an expression, so use [JS] to embed Go values.
+//
+// <button data-on:click="@setAll(true, {include: /^checkbox/})"></button>
+//
+// See https://data-star.dev/reference/actions#setall
+func SetAll(value string, filter Filter) string {
+	if filter.Include == "" && filter.Exclude == "" {
+		return callAction("setAll", value)
+	}
+	return callAction("setAll", value, toFilter(filter))
+}
+
+// ToggleAll toggles the boolean value of all signals matching the filter, or all signals if the filter is empty.
+//
+// <button data-on:click="@toggleAll({include: /^checkbox/})"></button>
+//
+// See https://data-star.dev/reference/actions#toggleall
+func ToggleAll(filter Filter) string {
+	if filter.Include == "" && filter.Exclude == "" {
+		return callAction("toggleAll")
+	}
+	return callAction("toggleAll", toFilter(filter))
+}
+
+// Clipboard copies the text to the clipboard. This is a Datastar Pro action.
+// The text is an expression, so use [Str] or [JS] for literal text.
+//
+// <button data-on:click="@clipboard($code)"></button>
+//
+// See https://data-star.dev/reference/actions#clipboard
+func Clipboard(text string) string {
+	return callAction("clipboard", text)
+}
+
+// Fit linearly maps the value from the old range to the new range. This is a Datastar Pro action.
+// If clamp is true, the result is clamped to the new range, and if round is true, it's rounded to the nearest integer.
+// All values are expressions.
+//
+// <div data-computed:percentage="@fit($slider, 0, 100, 0, 1, true)"></div>
+//
+// See https://data-star.dev/reference/actions#fit
+func Fit(value, oldMin, oldMax, newMin, newMax string, clamp, round bool) string {
+	args := []string{value, oldMin, oldMax, newMin, newMax}
+	switch {
+	case round:
+		args = append(args, strconv.FormatBool(clamp), "true")
+	case clamp:
+		args = append(args, "true")
+	}
+	return callAction("fit", args...)
+}
+
+// callAction returns the action with the expressions as arguments: @name(args...).
+func callAction(name string, args ...string) string {
+	return "@" + name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func action(name, url string, opts []ActionOption) string {
+	var o actionOptions
+	for _, opt := range opts {
+		opt.applyAction(&o)
+	}
+
+	v := "@" + name + "(" + quote(url)
+	if options := toActionOptions(o); options != "" {
+		v += ", " + options
+	}
+	v += ")"
+	return v
+}
+
+func toActionOptions(o actionOptions) string {
+	var pairs []string
+	if o.contentType != "" {
+		pairs = append(pairs, "contentType", quote(string(o.contentType)))
+	}
+	if o.filterSignals.Include != "" || o.filterSignals.Exclude != "" {
+		pairs = append(pairs, "filterSignals", toFilter(o.filterSignals))
+	}
+	if o.selector != "" {
+		pairs = append(pairs, "selector", quote(o.selector))
+	}
+	if len(o.headers) > 0 {
+		var headers []string
+		for i := 0; i < len(o.headers); i += 2 {
+			headers = append(headers, quote(o.headers[i]), quote(o.headers[i+1]))
+		}
+		pairs = append(pairs, "headers", toObject(headers))
+	}
+	if o.openWhenHidden != nil {
+		pairs = append(pairs, "openWhenHidden", strconv.FormatBool(*o.openWhenHidden))
+	}
+	if o.payload != "" {
+		pairs = append(pairs, "payload", o.payload)
+	}
+	if o.requestCancellation != "" {
+		pairs = append(pairs, "requestCancellation", quote(string(o.requestCancellation)))
+	}
+	if o.retry != "" {
+		pairs = append(pairs, "retry", quote(string(o.retry)))
+	}
+	if o.retryInterval != nil {
+		pairs = append(pairs, "retryInterval", toMilliseconds(*o.retryInterval))
+	}
+	if o.retryScaler != nil {
+		pairs = append(pairs, "retryScaler", strconv.FormatFloat(*o.retryScaler, 'f', -1, 64))
+	}
+	if o.retryMaxWait != nil {
+		pairs = append(pairs, "retryMaxWaitMs", toMilliseconds(*o.retryMaxWait))
+	}
+	if o.retryMaxCount != nil {
+		pairs = append(pairs, "retryMaxCount", strconv.Itoa(*o.retryMaxCount))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+	return toObject(pairs)
+}
+
+func toMilliseconds(d time.Duration) string {
+	return strconv.FormatInt(d.Round(time.Millisecond).Milliseconds(), 10)
+}
@@ -0,0 +1,184 @@
+package datastar_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "maragu.dev/gomponents/html"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+func TestGet(t *testing.T) {
+	t.Run(`should output @get('/endpoint')`, func(t *testing.T) {
+		n := Button(data.On("click", data.Get("/endpoint")))
+		assert.Equal(t, `<button data-on:click="@get(&#39;/endpoint&#39;)"></button>`, n)
+	})
+
+	t.Run(`should escape the URL`, func(t *testing.T) {
+		assert.EqualString(t, `@get('/search?q=it\'s\x3C/script>\n')`, data.Get("/search?q=it's</script>\n"))
+	})
+
+	t.Run(`should output options in a fixed order`, func(t *testing.T) {
+		expression := data.Get("/endpoint",
+			data.WithRetryMaxCount(3),
+			data.WithRetryMaxWait(10*time.Second),
+			data.WithRetryScaler(1.5),
+			data.WithRetryInterval(500*time.Millisecond),
+			data.WithRetry(data.RetryError),
+			data.WithRequestCancellation(data.RequestCancellationDisabled),
+			data.WithPayload("{foo: $foo}"),
+			data.WithOpenWhenHidden(true),
+			data.WithHeader("X-Csrf-Token", "abc"),
+			data.WithHeader("X-Other", "def"),
+			data.WithSelector("#myform"),
+			data.WithFilterSignals(data.Filter{Include: "/^foo/", Exclude: "/bar$/"}),
+			data.WithContentType(data.ContentTypeForm),
+		)
+		assert.EqualString(t, `@get('/endpoint', {`+
+			`contentType: 'form', `+
+			`filterSignals: {include: /^foo/, exclude: /bar$/}, `+
+			`selector: '#myform', `+
+			`headers: {'X-Csrf-Token': 'abc', 'X-Other': 'def'}, `+
+			`openWhenHidden: true, `+
+			`payload: {foo: $foo}, `+
+			`requestCancellation: 'disabled', `+
+			`retry: 'error', `+
+			`retryInterval: 500, `+
+			`retryScaler: 1.5, `+
+			`retryMaxWaitMs: 10000, `+
+			`retryMaxCount: 3})`, expression)
+	})
+
+	t.Run(`should panic on negative retry interval`, func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic for negative retry interval")
+			}
+		}()
+		data.WithRetryInterval(-1)
+	})
+
+	t.Run(`should panic on negative retry max wait`, func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic for negative retry max wait")
+			}
+		}()
+		data.WithRetryMaxWait(-1)
+	})
+}
+
+func TestPost(t *testing.T) {
+	t.Run(`should output @post('/endpoint')`, func(t *testing.T) {
+		assert.EqualString(t, `@post('/endpoint')`, data.Post("/endpoint"))
+	})
+
+	t.Run(`should output @post('/endpoint', {contentType: 'form'})`, func(t *testing.T) {
+		assert.EqualString(t, `@post('/endpoint', {contentType: 'form'})`, data.Post("/endpoint", data.WithContentType(data.ContentTypeForm)))
+	})
+}
+
+func TestPut(t *testing.T) {
+	t.Run(`should output @put('/endpoint')`, func(t *testing.T) {
+		assert.EqualString(t, `@put('/endpoint')`, data.Put("/endpoint"))
+	})
+}
+
+func TestPatch(t *testing.T) {
+	t.Run(`should output @patch('/endpoint')`, func(t *testing.T) {
+		assert.EqualString(t, `@patch('/endpoint')`, data.Patch("/endpoint"))
+	})
+}
+
+func TestDelete(t *testing.T) {
+	t.Run(`should output @delete('/endpoint')`, func(t *testing.T) {
+		assert.EqualString(t, `@delete('/endpoint')`, data.Delete("/endpoint"))
+	})
+}
+
+func TestPeek(t *testing.T) {
+	t.Run("should output @peek(() => $bar)", func(t *testing.T) {
+		assert.EqualString(t, "@peek(() => $bar)", data.Peek("$bar"))
+	})
+
+	t.Run("should work in other expressions", func(t *testing.T) {
+		n := Div(data.Text("$foo + " + data.Peek("$bar")))
+		assert.Equal(t, `<div data-text="$foo + @peek(() =&gt; $bar)"></div>`, n)
+	})
+}
+
+func TestSetAll(t *testing.T) {
+	t.Run("should output @setAll(true)", func(t *testing.T) {
+		assert.EqualString(t, "@setAll(true)", data.SetAll("true", data.Filter{}))
+	})
+
+	t.Run("should output @setAll(true, {include: /^checkbox/, exclude: /disabled/})", func(t *testing.T) {
+		assert.EqualString(t, "@setAll(true, {include: /^checkbox/, exclude: /disabled/})",
+			data.SetAll("true", data.Filter{Include: "/^checkbox/", Exclude: "/disabled/"}))
+	})
+
+	t.Run("should output escaped values from JS", func(t *testing.T) {
+		assert.EqualString(t, `@setAll('it\'s', {include: /^name/})`, data.SetAll(data.JS("it's"), data.Filter{Include: "/^name/"}))
+	})
+}
+
+func TestToggleAll(t *testing.T) {
+	t.Run("should output @toggleAll()", func(t *testing.T) {
+		assert.EqualString(t, "@toggleAll()", data.ToggleAll(data.Filter{}))
+	})
+
+	t.Run("should output @toggleAll({include: /^checkbox/})", func(t *testing.T) {
+		assert.EqualString(t, "@toggleAll({include: /^checkbox/})", data.ToggleAll(data.Filter{Include: "/^checkbox/"}))
+	})
+}
+
+func TestClipboard(t *testing.T) {
+	t.Run("should output @clipboard($code)", func(t *testing.T) {
+		assert.EqualString(t, "@clipboard($code)", data.Clipboard("$code"))
+	})
+
+	t.Run("should output @clipboard('Hello')", func(t *testing.T) {
+		assert.EqualString(t, "@clipboard('Hello')", data.Clipboard(data.Str("Hello").String()))
+	})
+}
+
+func TestFit(t *testing.T) {
+	t.Run("should output @fit($slider, 0, 100, 0, 1)", func(t *testing.T) {
+		assert.EqualString(t, "@fit($slider, 0, 100, 0, 1)", data.Fit("$slider", "0", "100", "0", "1", false, false))
+	})
+
+	t.Run("should output @fit($slider, 0, 100, 0, 1, true)", func(t *testing.T) {
+		assert.EqualString(t, "@fit($slider, 0, 100, 0, 1, true)", data.Fit("$slider", "0", "100", "0", "1", true, false))
+	})
+
+	t.Run("should output @fit($slider, 0, 100, 0, 1, false, true)", func(t *testing.T) {
+		assert.EqualString(t, "@fit($slider, 0, 100, 0, 1, false, true)", data.Fit("$slider", "0", "100", "0", "1", false, true))
+	})
+
+	t.Run("should output @fit($slider, 0, 100, 0, 1, true, true)", func(t *testing.T) {
+		assert.EqualString(t, "@fit($slider, 0, 100, 0, 1, true, true)", data.Fit("$slider", "0", "100", "0", "1", true, true))
+	})
+}
+
+func ExampleGet() {
+	fmt.Print(Button(data.On("click", data.Get("/endpoint"))))
+	// Output: <button data-on:click="@get(&#39;/endpoint&#39;)"></button>
+}
+
+func ExampleGet_withOptions() {
+	fmt.Print(Div(data.Init(data.Get("/updates", data.WithOpenWhenHidden(true), data.WithFilterSignals(data.Filter{Include: "/^user/"})))))
+	// Output: <div data-init="@get(&#39;/updates&#39;, {filterSignals: {include: /^user/}, openWhenHidden: true})"></div>
+}
+
+func ExamplePost() {
+	fmt.Print(Form(data.On("submit", data.Post("/signup", data.WithContentType(data.ContentTypeForm)))))
+	// Output: <form data-on:submit="@post(&#39;/signup&#39;, {contentType: &#39;form&#39;})"></form>
+}
+
+func ExampleToggleAll() {
+	fmt.Print(Button(data.On("click", data.ToggleAll(data.Filter{Include: "/^selected\\./"}))))
+	// Output: <button data-on:click="@toggleAll({include: /^selected\./})"></button>
+}
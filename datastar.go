@@ -1,4 +1,5 @@
-// Package datastar provides Datastar attributes and helpers for gomponents.
+// Package datastar provides Datastar attributes and helpers for gomponents,
+// as well as Server-Sent Events for responding to Datastar backend actions.
 // See https://data-star.dev
 package datastar
 
@@ -6,77 +7,66 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
-	"time"
 
 	g "maragu.dev/gomponents"
 	"maragu.dev/gomponents/html"
 )
 
-type Modifier string
-
 type Filter struct {
 	Include string
 	Exclude string
 }
 
-const (
-	ModifierCapture        Modifier = "__capture"
-	ModifierCase           Modifier = "__case"
-	ModifierDebounce       Modifier = "__debounce"
-	ModifierDelay          Modifier = "__delay"
-	ModifierDuration       Modifier = "__duration"
-	ModifierExit           Modifier = "__exit"
-	ModifierFull           Modifier = "__full"
-	ModifierHalf           Modifier = "__half"
-	ModifierIfMissing      Modifier = "__ifmissing"
-	ModifierOnce           Modifier = "__once"
-	ModifierOutside        Modifier = "__outside"
-	ModifierPassive        Modifier = "__passive"
-	ModifierPrevent        Modifier = "__prevent"
-	ModifierSelf           Modifier = "__self"
-	ModifierStop           Modifier = "__stop"
-	ModifierTerse          Modifier = "__terse"
-	ModifierThreshold      Modifier = "__threshold"
-	ModifierThrottle       Modifier = "__throttle"
-	ModifierViewTransition Modifier = "__viewtransition"
-	ModifierWindow         Modifier = "__window"
-)
+// Keyframe is an animated property and the expression for its target value. See [Animate].
+type Keyframe struct {
+	Property   string
+	Expression string
+}
 
-const (
-	ModifierCamel      Modifier = ".camel" // Camel case: myEvent
-	ModifierKebab      Modifier = ".kebab" // Kebab case: my-event
-	ModifierLeading    Modifier = ".leading"
-	ModifierNoLeading  Modifier = ".noleading"
-	ModifierNoTrailing Modifier = ".notrailing"
-	ModifierPascal     Modifier = ".pascal" // Pascal case: MyEvent
-	ModifierSnake      Modifier = ".snake"  // Snake case: my_event
-	ModifierTrailing   Modifier = ".trailing"
-)
+// Keyframes are the properties animated by [Animate], in order.
+type Keyframes []Keyframe
 
-// Duration outputs millisecond values for durations, rounded to the nearest millisecond.
-// Panics if the duration is negative.
-func Duration(d time.Duration) Modifier {
-	if d < 0 {
-		panic(fmt.Sprintf("duration must not be negative, but is: %v", d))
+// Animate animates element properties over time, towards the values of the expressions.
+// The animation runs again whenever signals in the expressions change. This is a Datastar Pro attribute.
+//
+//	data.Animate(data.Keyframes{
+//		{Property: "opacity", Expression: "$visible ? 1 : 0"},
+//		{Property: "background-color", Expression: "$error ? 'red' : 'white'"},
+//	}, data.ModifierDuration, data.Duration(300*time.Millisecond), data.ModifierEasing, data.ModifierEaseInOut)
+//
+// <div data-animate__duration.300ms__easing.ease-in-out="{opacity: $visible ? 1 : 0, 'background-color': $error ? 'red' : 'white'}"></div>
+//
+// Properties that aren't valid JavaScript identifiers are quoted. The expressions are used as-is, so use [JS] to embed Go values.
+// The __duration and __delay modifiers take a [Duration], and the __easing modifier takes an easing such as [ModifierEaseOut].
+// Panics if there are no keyframes, or a property is empty.
+//
+// See https://data-star.dev/reference/attributes#data-animate
+func Animate(keyframes Keyframes, modifiers ...AnimateModifier) g.Node {
+	if len(keyframes) == 0 {
+		panic("animate must have at least one keyframe")
 	}
-	return Modifier(fmt.Sprintf(".%vms", d.Round(time.Millisecond).Milliseconds()))
-}
 
-// Threshold outputs a visibility percentage threshold for the __threshold modifier.
-// The value must be between 0.0 (exclusive) and 1.0 (inclusive).
-// For values less than 1.0, the value is rounded to two decimal places (e.g., 0.25 for 25% visibility).
-// For the value 1.0, it is formatted as ".100" representing 100% visibility.
-// Panics if the threshold is outside the valid range.
-func Threshold(threshold float64) Modifier {
-	if threshold <= 0 || threshold > 1 {
-		panic(fmt.Sprintf("threshold must be between 0.0 (exclusive) and 1.0 (inclusive), but is: %v", threshold))
+	v := "{"
+	for i, k := range keyframes {
+		if k.Property == "" {
+			panic("keyframe property must not be empty")
+		}
+		if i > 0 {
+			v += ", "
+		}
+		property := k.Property
+		if !isIdentifier(property) {
+			property = quote(property)
+		}
+		v += property + ": " + k.Expression
 	}
-	// Special case: 1 represents 100% visibility
-	if threshold == 1 {
-		return Modifier(".100")
+	v += "}"
+
+	nameWithModifiers := ""
+	for _, modifier := range modifiers {
+		nameWithModifiers += modifier.String()
 	}
-	// Round to 2 decimal places and remove leading "0"
-	return Modifier(strings.TrimPrefix(fmt.Sprintf("%.2f", threshold), "0"))
+	return data("animate"+nameWithModifiers, v)
 }
 
 // Attr sets the value of any HTML attribute to an expression, and keeps it in sync.
@@ -88,6 +78,10 @@ func Threshold(threshold float64) Modifier {
 //
 // <div data-attr="{title: $foo, disabled: $bar}"></div>
 //
+// The values are expressions. Use [JS] to safely embed Go values, such as user data, as literals.
+//
+// <div data-attr="{title: 'It\'s mine'}"></div>
+//
 // See https://data-star.dev/reference/attributes#data-attr
 func Attr(pairs ...string) g.Node {
 	if len(pairs)%2 == 1 {
@@ -96,6 +90,19 @@ func Attr(pairs ...string) g.Node {
 	return data("attr", toObject(pairs))
 }
 
+// AttrKey is like [Attr], but sets the value of a single attribute using the keyed form.
+// The key is used as the attribute name as is, and data-attr has no modifiers, so AttrKey takes none.
+//
+// <div data-attr:title="$title"></div>
+//
+// Panics if the key is not a valid attribute key. See [ClassKey].
+//
+// See https://data-star.dev/reference/attributes#data-attr
+func AttrKey[E Expression](name string, expression E) g.Node {
+	mustValidateKey(name)
+	return data("attr:"+name, exprCode(expression))
+}
+
 // Bind creates a signal (if one doesn’t already exist) and sets up two-way data binding between it and an element’s value.
 // This means that the value of the element is updated when the signal changes, and the signal value is updated when the value of the element changes.
 //
@@ -118,11 +125,31 @@ func Attr(pairs ...string) g.Node {
 // <input data-bind-foo value="bar" />
 // </div>
 //
+// Panics if the name is not a valid signal name. See [ValidateSignalName].
+//
 // See https://data-star.dev/reference/attributes#data-bind
 func Bind(name string) g.Node {
+	mustValidateSignalName(name)
 	return data("bind", name)
 }
 
+// BindKey is like [Bind], but specifies the signal name in the key.
+//
+// <input data-bind:foo />
+//
+// The key is converted from kebab case to the signal name, so it may contain hyphens, such as "foo-bar" for the signal fooBar.
+// Panics if the key is not a valid attribute key (see [ClassKey]), or not a valid signal name (see [ValidateSignalName]).
+//
+// See https://data-star.dev/reference/attributes#data-bind
+func BindKey(name string, modifiers ...BindModifier) g.Node {
+	mustValidateSignalKey(name)
+	nameWithModifiers := name
+	for _, modifier := range modifiers {
+		nameWithModifiers += modifier.String()
+	}
+	return data("bind:" + nameWithModifiers)
+}
+
 // Class adds or removes a class to or from an element based on an expression.
 //
 // <div data-class-hidden="$foo"></div>
@@ -142,6 +169,27 @@ func Class(pairs ...string) g.Node {
 	return data("class", toObject(pairs))
 }
 
+// ClassKey is like [Class], but adds or removes a single class using the keyed form,
+// which is easier to read and can be overridden per element.
+//
+// <div data-class:hidden="$foo"></div>
+//
+// Browsers lowercase attribute names, so keys are converted from kebab case by default.
+// Use [ModifierCase] with a case modifier such as [ModifierCamel] to change that.
+//
+// Panics if the key is empty, contains a double underscore (__), which is the modifier delimiter,
+// or contains characters that are not allowed in attribute names.
+//
+// See https://data-star.dev/reference/attributes#data-class
+func ClassKey[E Expression](name string, expression E, modifiers ...ClassModifier) g.Node {
+	mustValidateKey(name)
+	nameWithModifiers := name
+	for _, modifier := range modifiers {
+		nameWithModifiers += modifier.String()
+	}
+	return data("class:"+nameWithModifiers, exprCode(expression))
+}
+
 // Computed creates a signal that is computed based on an expression. The computed signal is read-only,
 // and its value is automatically updated when any signals in the expression are updated.
 //
@@ -168,14 +216,45 @@ func Computed(pairs ...string) g.Node {
 	return data("computed", toComputed(pairs))
 }
 
+// ComputedKey is like [Computed], but creates a single computed signal using the keyed form.
+// The value is the expression itself, not a function.
+//
+// <div data-computed:foo="$bar + $baz"></div>
+//
+// The key is converted from kebab case to the signal name, so it may contain hyphens, such as "foo-bar" for the signal fooBar.
+// Panics if the key is not a valid attribute key (see [ClassKey]), or not a valid signal name (see [ValidateSignalName]).
+//
+// See https://data-star.dev/reference/attributes#data-computed
+func ComputedKey[E Expression](name string, expression E, modifiers ...ComputedModifier) g.Node {
+	mustValidateSignalKey(name)
+	nameWithModifiers := name
+	for _, modifier := range modifiers {
+		nameWithModifiers += modifier.String()
+	}
+	return data("computed:"+nameWithModifiers, exprCode(expression))
+}
+
+// CustomValidity sets a custom validity message on an input element, using an expression that evaluates to a string.
+// If the string is empty, the input is valid, otherwise it is invalid and the string is shown as the message.
+// This is a Datastar Pro attribute.
+//
+//	<input data-bind:foo data-custom-validity="$foo === $bar ? '' : 'Values must be the same.'" />
+//
+// See [Field] for a way to generate this from validation rules.
+//
+// See https://data-star.dev/reference/attributes#data-custom-validity
+func CustomValidity[E Expression](expression E) g.Node {
+	return data("custom-validity", exprCode(expression))
+}
+
 // Effect executes an expression on page load and whenever any signals in the expression change.
 // This is useful for performing side effects, such as updating other signals, making requests to the backend, or manipulating the DOM.
 //
 // <div data-effect="$foo = $bar + $baz"></div>
 //
 // See https://data-star.dev/reference/attributes#data-effect
-func Effect(expression string) g.Node {
-	return data("effect", expression)
+func Effect[E Expression](expression E) g.Node {
+	return data("effect", exprCode(expression))
 }
 
 // Ignore tells Datastar to ignore an element and its descendants.
@@ -192,10 +271,10 @@ func Effect(expression string) g.Node {
 // </div>
 //
 // See https://data-star.dev/reference/attributes#data-ignore
-func Ignore(modifiers ...Modifier) g.Node {
+func Ignore(modifiers ...IgnoreModifier) g.Node {
 	eventWithModifiers := ""
 	for _, modifier := range modifiers {
-		eventWithModifiers += string(modifier)
+		eventWithModifiers += modifier.String()
 	}
 	return data("ignore" + eventWithModifiers)
 }
@@ -222,11 +301,14 @@ func IgnoreMorph() g.Node {
 //
 // <div data-indicator-fetching data-init="@get('/endpoint')"></div>
 //
+// Panics if the name is not a valid signal name. See [ValidateSignalName].
+//
 // See https://data-star.dev/reference/attributes#data-indicator
-func Indicator(name string, modifiers ...Modifier) g.Node {
+func Indicator(name string, modifiers ...IndicatorModifier) g.Node {
+	mustValidateSignalName(name)
 	nameWithModifiers := ""
 	for _, modifier := range modifiers {
-		nameWithModifiers += string(modifier)
+		nameWithModifiers += modifier.String()
 	}
 	return data("indicator"+nameWithModifiers, name)
 }
@@ -248,10 +330,10 @@ func Indicator(name string, modifiers ...Modifier) g.Node {
 // <pre data-json-signals></pre>
 //
 // See https://data-star.dev/reference/attributes#data-json-signals
-func JSONSignals(filter Filter, modifiers ...Modifier) g.Node {
+func JSONSignals(filter Filter, modifiers ...JSONSignalsModifier) g.Node {
 	nameWithModifiers := ""
 	for _, modifier := range modifiers {
-		nameWithModifiers += string(modifier)
+		nameWithModifiers += modifier.String()
 	}
 	if filter.Include == "" && filter.Exclude == "" {
 		return data("json-signals" + nameWithModifiers)
@@ -270,12 +352,12 @@ func JSONSignals(filter Filter, modifiers ...Modifier) g.Node {
 // The `data-on` attribute works with events and custom events. The `data-on-submit` event listener prevents the default submission behavior of forms.
 //
 // See https://data-star.dev/reference/attributes#data-on
-func On(event, expression string, modifiers ...Modifier) g.Node {
+func On[E Expression](event string, expression E, modifiers ...OnModifier) g.Node {
 	eventWithModifiers := event
 	for _, modifier := range modifiers {
-		eventWithModifiers += string(modifier)
+		eventWithModifiers += modifier.String()
 	}
-	return data("on:"+eventWithModifiers, expression)
+	return data("on:"+eventWithModifiers, exprCode(expression))
 }
 
 // OnIntersect runs an expression when the element intersects with the viewport.
@@ -283,12 +365,12 @@ func On(event, expression string, modifiers ...Modifier) g.Node {
 // <div data-on-intersect="$intersected = true"></div>
 //
 // See https://data-star.dev/reference/attributes#data-on-intersect
-func OnIntersect(expression string, modifiers ...Modifier) g.Node {
+func OnIntersect[E Expression](expression E, modifiers ...OnIntersectModifier) g.Node {
 	eventWithModifiers := ""
 	for _, modifier := range modifiers {
-		eventWithModifiers += string(modifier)
+		eventWithModifiers += modifier.String()
 	}
-	return data("on-intersect"+eventWithModifiers, expression)
+	return data("on-intersect"+eventWithModifiers, exprCode(expression))
 }
 
 // OnInterval runs an expression at a regular interval. The interval duration defaults to one second and can be modified using the __duration modifier.
@@ -296,12 +378,44 @@ func OnIntersect(expression string, modifiers ...Modifier) g.Node {
 // <div data-on-interval="$count++"></div>
 //
 // See https://data-star.dev/reference/attributes#data-on-interval
-func OnInterval(expression string, modifiers ...Modifier) g.Node {
+func OnInterval[E Expression](expression E, modifiers ...OnIntervalModifier) g.Node {
+	eventWithModifiers := ""
+	for _, modifier := range modifiers {
+		eventWithModifiers += modifier.String()
+	}
+	return data("on-interval"+eventWithModifiers, exprCode(expression))
+}
+
+// OnRAF runs an expression on every requestAnimationFrame event.
+// This is a Datastar Pro attribute.
+//
+// <div data-on-raf="$count++"></div>
+//
+// The __throttle modifier limits how often the expression runs.
+//
+// See https://data-star.dev/reference/attributes#data-on-raf
+func OnRAF[E Expression](expression E, modifiers ...OnRAFModifier) g.Node {
+	eventWithModifiers := ""
+	for _, modifier := range modifiers {
+		eventWithModifiers += modifier.String()
+	}
+	return data("on-raf"+eventWithModifiers, exprCode(expression))
+}
+
+// OnResize runs an expression whenever the dimensions of the element change.
+// This is a Datastar Pro attribute.
+//
+// <div data-on-resize="$count++"></div>
+//
+// The __debounce and __throttle modifiers limit how often the expression runs.
+//
+// See https://data-star.dev/reference/attributes#data-on-resize
+func OnResize[E Expression](expression E, modifiers ...OnResizeModifier) g.Node {
 	eventWithModifiers := ""
 	for _, modifier := range modifiers {
-		eventWithModifiers += string(modifier)
+		eventWithModifiers += modifier.String()
 	}
-	return data("on-interval"+eventWithModifiers, expression)
+	return data("on-resize"+eventWithModifiers, exprCode(expression))
 }
 
 // Init runs an expression when an element is loaded into the DOM.
@@ -312,12 +426,12 @@ func OnInterval(expression string, modifiers ...Modifier) g.Node {
 // <div data-init="$count = 1"></div>
 //
 // See https://data-star.dev/reference/attributes#data-init
-func Init(expression string, modifiers ...Modifier) g.Node {
+func Init[E Expression](expression E, modifiers ...InitModifier) g.Node {
 	eventWithModifiers := ""
 	for _, modifier := range modifiers {
-		eventWithModifiers += string(modifier)
+		eventWithModifiers += modifier.String()
 	}
-	return data("init"+eventWithModifiers, expression)
+	return data("init"+eventWithModifiers, exprCode(expression))
 }
 
 // OnSignalPatch runs an expression whenever one or more signals are patched.
@@ -332,12 +446,12 @@ func Init(expression string, modifiers ...Modifier) g.Node {
 // You can filter which signals to watch using the data-on-signal-patch-filter attribute.
 //
 // See https://data-star.dev/reference/attributes#data-on-signal-patch
-func OnSignalPatch(expression string, modifiers ...Modifier) g.Node {
+func OnSignalPatch[E Expression](expression E, modifiers ...OnSignalPatchModifier) g.Node {
 	eventWithModifiers := ""
 	for _, modifier := range modifiers {
-		eventWithModifiers += string(modifier)
+		eventWithModifiers += modifier.String()
 	}
-	return data("on-signal-patch"+eventWithModifiers, expression)
+	return data("on-signal-patch"+eventWithModifiers, exprCode(expression))
 }
 
 // OnSignalPatchFilter filters which signals to watch when using the `data-on-signal-patch` attribute.
@@ -349,6 +463,35 @@ func OnSignalPatchFilter(filter Filter) g.Node {
 	return data("on-signal-patch-filter", toFilter(filter))
 }
 
+// Persist persists signals in local storage, so they keep their values when the page is reloaded.
+// This is a Datastar Pro attribute.
+//
+// <div data-persist></div>
+//
+// The filter includes or excludes signals using regular expressions, like [JSONSignals].
+// The key sets the storage key, which defaults to "datastar" if empty.
+//
+// <div data-persist:mykey="{include: /^user\./}"></div>
+//
+// The __session modifier uses session storage instead of local storage.
+// Panics if the key is not a valid attribute key. See [ClassKey].
+//
+// See https://data-star.dev/reference/attributes#data-persist
+func Persist(key string, filter Filter, modifiers ...PersistModifier) g.Node {
+	name := "persist"
+	if key != "" {
+		mustValidateKey(key)
+		name += ":" + key
+	}
+	for _, modifier := range modifiers {
+		name += modifier.String()
+	}
+	if filter.Include == "" && filter.Exclude == "" {
+		return data(name)
+	}
+	return data(name, toFilter(filter))
+}
+
 // PreserveAttr preserves the value of an attribute when morphing DOM elements.
 //
 // <details open data-preserve-attr="open">
@@ -365,6 +508,37 @@ func PreserveAttr(attrs ...string) g.Node {
 	return data("preserve-attr", strings.Join(attrs, " "))
 }
 
+// QueryString syncs signals with query string parameters in the URL, both on page load and when the signals change.
+// This is a Datastar Pro attribute.
+//
+// <div data-query-string="{include: /^search/}"></div>
+//
+// The filter includes or excludes signals using regular expressions, like [JSONSignals].
+// The __filter modifier leaves out signals with empty values,
+// and the __history modifier adds a history entry for each change, instead of replacing the current one.
+//
+// See https://data-star.dev/reference/attributes#data-query-string
+func QueryString(filter Filter, modifiers ...QueryStringModifier) g.Node {
+	nameWithModifiers := ""
+	for _, modifier := range modifiers {
+		nameWithModifiers += modifier.String()
+	}
+	if filter.Include == "" && filter.Exclude == "" {
+		return data("query-string" + nameWithModifiers)
+	}
+	return data("query-string"+nameWithModifiers, toFilter(filter))
+}
+
+// ReplaceURL replaces the URL in the browser, without reloading the page, whenever the expression changes.
+// The value can be a relative or absolute URL. This is a Datastar Pro attribute.
+//
+// <div data-replace-url="`/page${$page}`"></div>
+//
+// See https://data-star.dev/reference/attributes#data-replace-url
+func ReplaceURL[E Expression](expression E) g.Node {
+	return data("replace-url", exprCode(expression))
+}
+
 // Ref creates a new signal that is a reference to the element on which the data attribute is placed.
 //
 // <div data-ref="foo"></div>
@@ -373,15 +547,37 @@ func PreserveAttr(attrs ...string) g.Node {
 //
 // $foo is a reference to a <span data-text="$foo.tagName"></span> element
 //
+// Panics if the name is not a valid signal name. See [ValidateSignalName].
+//
 // See https://data-star.dev/reference/attributes#data-ref
-func Ref(name string, modifiers ...Modifier) g.Node {
+func Ref(name string, modifiers ...RefModifier) g.Node {
+	mustValidateSignalName(name)
 	nameWithModifiers := ""
 	for _, modifier := range modifiers {
-		nameWithModifiers += string(modifier)
+		nameWithModifiers += modifier.String()
 	}
 	return data("ref"+nameWithModifiers, name)
 }
 
+// ScrollIntoView scrolls the element into view. Useful when updating the DOM from the backend,
+// and you want to scroll to the new content. This is a Datastar Pro attribute.
+//
+// <div data-scroll-into-view__smooth__vcenter></div>
+//
+// Modifiers set the scrolling behavior ([ModifierSmooth], [ModifierInstant], [ModifierAuto]),
+// the horizontal alignment ([ModifierHStart], [ModifierHCenter], [ModifierHEnd], [ModifierHNearest]),
+// the vertical alignment ([ModifierVStart], [ModifierVCenter], [ModifierVEnd], [ModifierVNearest]),
+// and whether to focus the element ([ModifierFocus]).
+//
+// See https://data-star.dev/reference/attributes#data-scroll-into-view
+func ScrollIntoView(modifiers ...ScrollIntoViewModifier) g.Node {
+	nameWithModifiers := ""
+	for _, modifier := range modifiers {
+		nameWithModifiers += modifier.String()
+	}
+	return data("scroll-into-view" + nameWithModifiers)
+}
+
 // Show or hide an element based on whether an expression evaluates to true or false.
 // For anything with custom requirements, use data-class instead.
 //
@@ -392,8 +588,8 @@ func Ref(name string, modifiers ...Modifier) g.Node {
 // <div data-show="$foo" style="display: none"></div>
 //
 // See https://data-star.dev/reference/attributes#data-show
-func Show(expression string) g.Node {
-	return data("show", expression)
+func Show[E Expression](expression E) g.Node {
+	return data("show", exprCode(expression))
 }
 
 // Signals patches (adds, updates or removes) one or more signals into the existing signals. Values defined later in the DOM tree override those defined earlier.
@@ -408,16 +604,36 @@ func Show(expression string) g.Node {
 // You can opt to include them by modifying the value of the filterSignals option.
 //
 // Signal names cannot begin with nor contain a double underscore (__), due to its use as a modifier delimiter.
+// Panics if any name, including names in nested maps, is not a valid signal name. See [ValidateSignals].
 //
 // See https://data-star.dev/reference/attributes#data-signals
-func Signals(signals map[string]any, modifiers ...Modifier) g.Node {
+func Signals(signals map[string]any, modifiers ...SignalsModifier) g.Node {
+	mustValidateSignals(signals)
 	nameWithModifiers := ""
 	for _, modifier := range modifiers {
-		nameWithModifiers += string(modifier)
+		nameWithModifiers += modifier.String()
 	}
 	return data("signals"+nameWithModifiers, toSignals(signals))
 }
 
+// SignalKey is like [Signals], but patches a single signal using the keyed form.
+// The value is an expression, so use [JS] to embed Go values.
+//
+// <div data-signals:foo="1"></div>
+//
+// The key is converted from kebab case to the signal name, so it may contain hyphens, such as "foo-bar" for the signal fooBar.
+// Panics if the key is not a valid attribute key (see [ClassKey]), or not a valid signal name (see [ValidateSignalName]).
+//
+// See https://data-star.dev/reference/attributes#data-signals
+func SignalKey[E Expression](name string, expression E, modifiers ...SignalsModifier) g.Node {
+	mustValidateSignalKey(name)
+	nameWithModifiers := name
+	for _, modifier := range modifiers {
+		nameWithModifiers += modifier.String()
+	}
+	return data("signals:"+nameWithModifiers, exprCode(expression))
+}
+
 // Style sets the value of inline CSS styles on an element based on an expression, and keeps them in sync.
 //
 // The data-style attribute can be used to set multiple style properties on an element using a set of key-value pairs,
@@ -445,6 +661,8 @@ func Signals(signals map[string]any, modifiers ...Modifier) g.Node {
 // The plugin tracks initial inline style values and restores them when data-style expressions become falsy or during cleanup.
 // This ensures existing inline styles are preserved and only the dynamic changes are managed by Datastar.
 //
+// The values are expressions. Use [JS] to safely embed Go values as literals.
+//
 // See https://data-star.dev/reference/attributes#data-style
 func Style(pairs ...string) g.Node {
 	if len(pairs)%2 == 1 {
@@ -453,13 +671,36 @@ func Style(pairs ...string) g.Node {
 	return data("style", toObject(pairs))
 }
 
+// StyleKey is like [Style], but sets a single style property using the keyed form.
+// The key is used as the CSS property name as is, and data-style has no modifiers, so StyleKey takes none.
+//
+// <div data-style:display="$hiding && 'none'"></div>
+//
+// Panics if the key is not a valid attribute key. See [ClassKey].
+//
+// See https://data-star.dev/reference/attributes#data-style
+func StyleKey[E Expression](name string, expression E) g.Node {
+	mustValidateKey(name)
+	return data("style:"+name, exprCode(expression))
+}
+
 // Text binds the text content of an element to an expression.
 //
 // <div data-text="$foo"></div>
 //
 // See https://data-star.dev/reference/attributes#data-text
-func Text(v string) g.Node {
-	return data("text", v)
+func Text[E Expression](expression E) g.Node {
+	return data("text", exprCode(expression))
+}
+
+// ViewTransition sets the view-transition-name style of the element to the value of an expression.
+// Use it together with the __viewtransition modifier or [WithViewTransition]. This is a Datastar Pro attribute.
+//
+// <div data-view-transition="$itemId"></div>
+//
+// See https://data-star.dev/reference/attributes#data-view-transition
+func ViewTransition[E Expression](expression E) g.Node {
+	return data("view-transition", exprCode(expression))
 }
 
 func toObject(pairs []string) string {
@@ -501,14 +742,23 @@ func toFilter(filter Filter) string {
 	return v
 }
 
-func toSignals(signals map[string]any) string {
-	b, err := json.Marshal(signals)
+func toSignals(signals any) string {
+	b, err := marshalSignals(signals)
 	if err != nil {
-		panic(fmt.Sprintf("failed to marshal signals: %v", err))
+		panic(err.Error())
 	}
 	return string(b)
 }
 
+// marshalSignals is the JSON encoding used for signals, both in attributes and in events.
+func marshalSignals(signals any) ([]byte, error) {
+	b, err := json.Marshal(signals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal signals: %w", err)
+	}
+	return b, nil
+}
+
 func data(name string, value ...string) g.Node {
 	if len(value) > 0 {
 		return html.Data(name, value[0])
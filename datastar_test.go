@@ -11,6 +11,44 @@ import (
 	"maragu.dev/gomponents-datastar/internal/assert"
 )
 
+func TestAnimate(t *testing.T) {
+	t.Run(`should output data-animate="{opacity: $visible ? 1 : 0}"`, func(t *testing.T) {
+		n := Div(data.Animate(data.Keyframes{{Property: "opacity", Expression: "$visible ? 1 : 0"}}))
+		assert.Equal(t, `<div data-animate="{opacity: $visible ? 1 : 0}"></div>`, n)
+	})
+
+	t.Run(`should output data-animate="{opacity: $opacity, 'background-color': $color}"`, func(t *testing.T) {
+		n := Div(data.Animate(data.Keyframes{{Property: "opacity", Expression: "$opacity"}, {Property: "background-color", Expression: "$color"}}))
+		assert.Equal(t, `<div data-animate="{opacity: $opacity, &#39;background-color&#39;: $color}"></div>`, n)
+	})
+
+	t.Run(`should output data-animate__duration.300ms__delay.50ms__easing.ease-in="{opacity: $opacity}"`, func(t *testing.T) {
+		n := Div(data.Animate(data.Keyframes{{Property: "opacity", Expression: "$opacity"}},
+			data.ModifierDuration, data.Duration(300*time.Millisecond),
+			data.ModifierDelay, data.Duration(50*time.Millisecond),
+			data.ModifierEasing, data.ModifierEaseIn))
+		assert.Equal(t, `<div data-animate__duration.300ms__delay.50ms__easing.ease-in="{opacity: $opacity}"></div>`, n)
+	})
+
+	t.Run("should panic without keyframes", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		data.Animate(nil)
+	})
+
+	t.Run("should panic on empty property", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		data.Animate(data.Keyframes{{Expression: "1"}})
+	})
+}
+
 func TestAttr(t *testing.T) {
 	t.Run(`should output data-attr="{title: $title}"`, func(t *testing.T) {
 		n := Div(data.Attr("title", "$title"))
@@ -23,6 +61,18 @@ func TestAttr(t *testing.T) {
 	})
 }
 
+func TestAttrKey(t *testing.T) {
+	t.Run(`should output data-attr:title="$title"`, func(t *testing.T) {
+		n := Div(data.AttrKey("title", "$title"))
+		assert.Equal(t, `<div data-attr:title="$title"></div>`, n)
+	})
+
+	t.Run(`should output data-attr:aria-label="$label"`, func(t *testing.T) {
+		n := Div(data.AttrKey("aria-label", "$label"))
+		assert.Equal(t, `<div data-attr:aria-label="$label"></div>`, n)
+	})
+}
+
 func TestBind(t *testing.T) {
 	t.Run(`should output data-bind="foo"`, func(t *testing.T) {
 		n := Input(data.Bind("foo"))
@@ -30,6 +80,27 @@ func TestBind(t *testing.T) {
 	})
 }
 
+func TestBindKey(t *testing.T) {
+	t.Run(`should output data-bind:foo`, func(t *testing.T) {
+		n := Input(data.BindKey("foo"))
+		assert.Equal(t, `<input data-bind:foo>`, n)
+	})
+
+	t.Run(`should output data-bind:foo-bar__case.snake`, func(t *testing.T) {
+		n := Input(data.BindKey("foo-bar", data.ModifierCase, data.ModifierSnake))
+		assert.Equal(t, `<input data-bind:foo-bar__case.snake>`, n)
+	})
+
+	t.Run("should panic on reserved signal names", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		data.BindKey("constructor")
+	})
+}
+
 func TestClass(t *testing.T) {
 	t.Run(`should output data-class="{hidden: $hidden}"`, func(t *testing.T) {
 		n := Div(data.Class("hidden", "$hidden"))
@@ -42,6 +113,45 @@ func TestClass(t *testing.T) {
 	})
 }
 
+func TestClassKey(t *testing.T) {
+	t.Run(`should output data-class:hidden="$hidden"`, func(t *testing.T) {
+		n := Div(data.ClassKey("hidden", "$hidden"))
+		assert.Equal(t, `<div data-class:hidden="$hidden"></div>`, n)
+	})
+
+	t.Run(`should output data-class:font-bold="$bold"`, func(t *testing.T) {
+		n := Div(data.ClassKey("font-bold", "$bold"))
+		assert.Equal(t, `<div data-class:font-bold="$bold"></div>`, n)
+	})
+
+	t.Run(`should output data-class:my-class__case.camel="$foo"`, func(t *testing.T) {
+		n := Div(data.ClassKey("my-class", "$foo", data.ModifierCase, data.ModifierCamel))
+		assert.Equal(t, `<div data-class:my-class__case.camel="$foo"></div>`, n)
+	})
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "empty", key: ""},
+		{name: "double underscore", key: "foo__bar"},
+		{name: "space", key: "foo bar"},
+		{name: "quote", key: `foo"bar`},
+		{name: "equals sign", key: "foo=bar"},
+		{name: "greater than", key: "foo>"},
+	}
+	for _, test := range tests {
+		t.Run("should panic on invalid key: "+test.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("expected panic")
+				}
+			}()
+			data.ClassKey(test.key, "$foo")
+		})
+	}
+}
+
 func TestComputed(t *testing.T) {
 	t.Run(`should output data-computed="{foo: () => $bar + $baz}"`, func(t *testing.T) {
 		n := Div(data.Computed("foo", "$bar + $baz"))
@@ -54,6 +164,25 @@ func TestComputed(t *testing.T) {
 	})
 }
 
+func TestComputedKey(t *testing.T) {
+	t.Run(`should output data-computed:foo="$bar + $baz"`, func(t *testing.T) {
+		n := Div(data.ComputedKey("foo", "$bar + $baz"))
+		assert.Equal(t, `<div data-computed:foo="$bar + $baz"></div>`, n)
+	})
+
+	t.Run(`should output data-computed:my-total__case.kebab="$price * $quantity"`, func(t *testing.T) {
+		n := Div(data.ComputedKey("my-total", "$price * $quantity", data.ModifierCase, data.ModifierKebab))
+		assert.Equal(t, `<div data-computed:my-total__case.kebab="$price * $quantity"></div>`, n)
+	})
+}
+
+func TestCustomValidity(t *testing.T) {
+	t.Run(`should output data-custom-validity="$error"`, func(t *testing.T) {
+		n := Input(data.CustomValidity("$error"))
+		assert.Equal(t, `<input data-custom-validity="$error">`, n)
+	})
+}
+
 func TestEffect(t *testing.T) {
 	t.Run(`should output data-effect="$foo = $bar + $baz"`, func(t *testing.T) {
 		n := Div(data.Effect("$foo = $bar + $baz"))
@@ -188,6 +317,30 @@ func TestOnInterval(t *testing.T) {
 	})
 }
 
+func TestOnRAF(t *testing.T) {
+	t.Run(`should output data-on-raf="$count++"`, func(t *testing.T) {
+		n := Div(data.OnRAF("$count++"))
+		assert.Equal(t, `<div data-on-raf="$count++"></div>`, n)
+	})
+
+	t.Run(`should output data-on-raf__throttle.10ms.trailing="$count++"`, func(t *testing.T) {
+		n := Div(data.OnRAF("$count++", data.ModifierThrottle, data.Duration(10*time.Millisecond), data.ModifierTrailing))
+		assert.Equal(t, `<div data-on-raf__throttle.10ms.trailing="$count++"></div>`, n)
+	})
+}
+
+func TestOnResize(t *testing.T) {
+	t.Run(`should output data-on-resize="$count++"`, func(t *testing.T) {
+		n := Div(data.OnResize("$count++"))
+		assert.Equal(t, `<div data-on-resize="$count++"></div>`, n)
+	})
+
+	t.Run(`should output data-on-resize__debounce.100ms="$count++"`, func(t *testing.T) {
+		n := Div(data.OnResize("$count++", data.ModifierDebounce, data.Duration(100*time.Millisecond)))
+		assert.Equal(t, `<div data-on-resize__debounce.100ms="$count++"></div>`, n)
+	})
+}
+
 func TestInit(t *testing.T) {
 	t.Run(`should output data-init="$count = 1"`, func(t *testing.T) {
 		n := Div(data.Init("$count = 1"))
@@ -241,6 +394,51 @@ func TestPreserveAttr(t *testing.T) {
 	})
 }
 
+func TestPersist(t *testing.T) {
+	t.Run(`should output data-persist`, func(t *testing.T) {
+		n := Div(data.Persist("", data.Filter{}))
+		assert.Equal(t, `<div data-persist></div>`, n)
+	})
+
+	t.Run(`should output data-persist="{include: /foo/, exclude: /bar/}"`, func(t *testing.T) {
+		n := Div(data.Persist("", data.Filter{Include: "/foo/", Exclude: "/bar/"}))
+		assert.Equal(t, `<div data-persist="{include: /foo/, exclude: /bar/}"></div>`, n)
+	})
+
+	t.Run(`should output data-persist:mykey__session="{include: /foo/}"`, func(t *testing.T) {
+		n := Div(data.Persist("mykey", data.Filter{Include: "/foo/"}, data.ModifierSession))
+		assert.Equal(t, `<div data-persist:mykey__session="{include: /foo/}"></div>`, n)
+	})
+
+	t.Run("should panic on invalid key", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		data.Persist("my__key", data.Filter{})
+	})
+}
+
+func TestQueryString(t *testing.T) {
+	t.Run(`should output data-query-string`, func(t *testing.T) {
+		n := Div(data.QueryString(data.Filter{}))
+		assert.Equal(t, `<div data-query-string></div>`, n)
+	})
+
+	t.Run(`should output data-query-string__filter__history="{exclude: /^_/}"`, func(t *testing.T) {
+		n := Div(data.QueryString(data.Filter{Exclude: "/^_/"}, data.ModifierFilter, data.ModifierHistory))
+		assert.Equal(t, `<div data-query-string__filter__history="{exclude: /^_/}"></div>`, n)
+	})
+}
+
+func TestReplaceURL(t *testing.T) {
+	t.Run("should output data-replace-url=\"`/page${$page}`\"", func(t *testing.T) {
+		n := Div(data.ReplaceURL("`/page${$page}`"))
+		assert.Equal(t, "<div data-replace-url=\"`/page${$page}`\"></div>", n)
+	})
+}
+
 func TestRef(t *testing.T) {
 	t.Run(`should output data-ref="foo"`, func(t *testing.T) {
 		n := Div(data.Ref("foo"))
@@ -266,6 +464,42 @@ func TestRef(t *testing.T) {
 	}
 }
 
+func TestScrollIntoView(t *testing.T) {
+	t.Run(`should output data-scroll-into-view`, func(t *testing.T) {
+		n := Div(data.ScrollIntoView())
+		assert.Equal(t, `<div data-scroll-into-view></div>`, n)
+	})
+
+	tests := []struct {
+		modifier data.ScrollModifier
+		expected string
+	}{
+		{data.ModifierSmooth, "__smooth"},
+		{data.ModifierInstant, "__instant"},
+		{data.ModifierAuto, "__auto"},
+		{data.ModifierHStart, "__hstart"},
+		{data.ModifierHCenter, "__hcenter"},
+		{data.ModifierHEnd, "__hend"},
+		{data.ModifierHNearest, "__hnearest"},
+		{data.ModifierVStart, "__vstart"},
+		{data.ModifierVCenter, "__vcenter"},
+		{data.ModifierVEnd, "__vend"},
+		{data.ModifierVNearest, "__vnearest"},
+		{data.ModifierFocus, "__focus"},
+	}
+	for _, test := range tests {
+		t.Run(`should output data-scroll-into-view`+test.expected, func(t *testing.T) {
+			n := Div(data.ScrollIntoView(test.modifier))
+			assert.Equal(t, `<div data-scroll-into-view`+test.expected+`></div>`, n)
+		})
+	}
+
+	t.Run(`should output data-scroll-into-view__smooth__vcenter__focus`, func(t *testing.T) {
+		n := Div(data.ScrollIntoView(data.ModifierSmooth, data.ModifierVCenter, data.ModifierFocus))
+		assert.Equal(t, `<div data-scroll-into-view__smooth__vcenter__focus></div>`, n)
+	})
+}
+
 func TestShow(t *testing.T) {
 	t.Run(`should output data-show="$foo"`, func(t *testing.T) {
 		n := Div(data.Show("$foo"))
@@ -300,6 +534,49 @@ func TestSignals(t *testing.T) {
 	})
 }
 
+func TestSignalKey(t *testing.T) {
+	t.Run(`should output data-signals:foo="1"`, func(t *testing.T) {
+		n := Div(data.SignalKey("foo", "1"))
+		assert.Equal(t, `<div data-signals:foo="1"></div>`, n)
+	})
+
+	t.Run(`should output data-signals:foo.bar="'baz'"`, func(t *testing.T) {
+		n := Div(data.SignalKey("foo.bar", data.JS("baz")))
+		assert.Equal(t, `<div data-signals:foo.bar="&#39;baz&#39;"></div>`, n)
+	})
+
+	t.Run(`should output data-signals:foo__ifmissing__case.kebab="1"`, func(t *testing.T) {
+		n := Div(data.SignalKey("foo", "1", data.ModifierIfMissing, data.ModifierCase, data.ModifierKebab))
+		assert.Equal(t, `<div data-signals:foo__ifmissing__case.kebab="1"></div>`, n)
+	})
+
+	t.Run(`should output data-signals:foo-bar="1"`, func(t *testing.T) {
+		n := Div(data.SignalKey("foo-bar", "1"))
+		assert.Equal(t, `<div data-signals:foo-bar="1"></div>`, n)
+	})
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "constructor", key: "constructor"},
+		{name: "reserved path segment", key: "foo.prototype"},
+		{name: "empty path segment", key: "foo..bar"},
+		{name: "leading digit", key: "1foo"},
+		{name: "invalid character", key: "foo$bar"},
+	}
+	for _, test := range tests {
+		t.Run("should panic on invalid signal name: "+test.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("expected panic")
+				}
+			}()
+			data.SignalKey(test.key, "1")
+		})
+	}
+}
+
 func TestStyle(t *testing.T) {
 	t.Run(`should output data-style="{display: $hiding ? 'none' : 'flex'}"`, func(t *testing.T) {
 		n := Div(data.Style("display", "$hiding ? 'none' : 'flex'"))
@@ -312,6 +589,18 @@ func TestStyle(t *testing.T) {
 	})
 }
 
+func TestStyleKey(t *testing.T) {
+	t.Run(`should output data-style:display="$hiding && 'none'"`, func(t *testing.T) {
+		n := Div(data.StyleKey("display", "$hiding && 'none'"))
+		assert.Equal(t, `<div data-style:display="$hiding &amp;&amp; &#39;none&#39;"></div>`, n)
+	})
+
+	t.Run(`should output data-style:background-color="$color"`, func(t *testing.T) {
+		n := Div(data.StyleKey("background-color", "$color"))
+		assert.Equal(t, `<div data-style:background-color="$color"></div>`, n)
+	})
+}
+
 func TestText(t *testing.T) {
 	t.Run(`should output data-text="$foo"`, func(t *testing.T) {
 		n := Div(data.Text("$foo"))
@@ -319,6 +608,21 @@ func TestText(t *testing.T) {
 	})
 }
 
+func TestViewTransition(t *testing.T) {
+	t.Run(`should output data-view-transition="$itemId"`, func(t *testing.T) {
+		n := Div(data.ViewTransition("$itemId"))
+		assert.Equal(t, `<div data-view-transition="$itemId"></div>`, n)
+	})
+}
+
+func ExampleAnimate() {
+	fmt.Print(Div(data.Animate(data.Keyframes{
+		{Property: "opacity", Expression: "$visible ? 1 : 0"},
+		{Property: "transform", Expression: data.JS("scale(1.1)")},
+	}, data.ModifierDuration, data.Duration(300*time.Millisecond), data.ModifierEasing, data.ModifierEaseInOut)))
+	// Output: <div data-animate__duration.300ms__easing.ease-in-out="{opacity: $visible ? 1 : 0, transform: &#39;scale(1.1)&#39;}"></div>
+}
+
 func ExampleAttr() {
 	fmt.Print(Div(data.Attr("title", "$title")))
 	// Output: <div data-attr="{title: $title}"></div>
@@ -344,6 +648,11 @@ func ExampleClass_multiple() {
 	// Output: <div data-class="{hidden: $hidden, font-bold: $bold}"></div>
 }
 
+func ExampleClassKey() {
+	fmt.Print(Div(data.ClassKey("hidden", "$hidden")))
+	// Output: <div data-class:hidden="$hidden"></div>
+}
+
 func ExampleComputed() {
 	fmt.Print(Div(data.Computed("foo", "$bar + $baz")))
 	// Output: <div data-computed="{foo: () =&gt; $bar + $baz}"></div>
@@ -429,6 +738,16 @@ func ExampleOnInterval_withDuration() {
 	// Output: <div data-on-interval__duration.500ms="$count++"></div>
 }
 
+func ExampleOnResize() {
+	fmt.Print(Div(data.OnResize("$width = el.offsetWidth", data.ModifierThrottle, data.Duration(50*time.Millisecond))))
+	// Output: <div data-on-resize__throttle.50ms="$width = el.offsetWidth"></div>
+}
+
+func ExampleScrollIntoView() {
+	fmt.Print(Div(data.ScrollIntoView(data.ModifierSmooth, data.ModifierVCenter)))
+	// Output: <div data-scroll-into-view__smooth__vcenter></div>
+}
+
 func ExampleInit() {
 	fmt.Print(Div(data.Init("$count = 1")))
 	// Output: <div data-init="$count = 1"></div>
@@ -608,3 +927,8 @@ func ExampleOnIntersect_withThreshold() {
 	fmt.Print(Div(data.OnIntersect("$visible = true", data.ModifierThreshold, data.Threshold(0.25))))
 	// Output: <div data-on-intersect__threshold.25="$visible = true"></div>
 }
+
+func ExampleQueryString() {
+	fmt.Print(Div(data.QueryString(data.Filter{Include: "/^search/"}, data.ModifierHistory)))
+	// Output: <div data-query-string__history="{include: /^search/}"></div>
+}
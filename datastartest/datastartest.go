@@ -0,0 +1,320 @@
+// Package datastartest provides utilities for testing handlers that respond to Datastar requests,
+// similar to [httptest].
+package datastartest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	datastar "maragu.dev/gomponents-datastar"
+)
+
+// Recorder is an [httptest.ResponseRecorder] that can parse the Server-Sent Events written to it.
+type Recorder struct {
+	*httptest.ResponseRecorder
+}
+
+// NewRecorder returns an initialized [Recorder].
+func NewRecorder() *Recorder {
+	return &Recorder{ResponseRecorder: httptest.NewRecorder()}
+}
+
+// Event is a parsed Server-Sent Event, either a [PatchElementsEvent] or a [PatchSignalsEvent].
+type Event interface {
+	EventType() string
+}
+
+// PatchElementsEvent is a parsed `datastar-patch-elements` event.
+type PatchElementsEvent struct {
+	ID                string
+	Retry             time.Duration
+	Selector          string
+	Mode              datastar.PatchMode
+	UseViewTransition bool
+	Elements          string
+}
+
+// EventType satisfies [Event].
+func (PatchElementsEvent) EventType() string {
+	return datastar.EventTypePatchElements
+}
+
+// PatchSignalsEvent is a parsed `datastar-patch-signals` event.
+type PatchSignalsEvent struct {
+	ID            string
+	Retry         time.Duration
+	OnlyIfMissing bool
+	Signals       map[string]any
+}
+
+// EventType satisfies [Event].
+func (PatchSignalsEvent) EventType() string {
+	return datastar.EventTypePatchSignals
+}
+
+// Parse the Server-Sent Events in r.
+// Returns an error for malformed events and unknown event types.
+func Parse(r io.Reader) ([]Event, error) {
+	var events []Event
+	var typ, id string
+	var retry time.Duration
+	var data []string
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := readLine(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if line == "" {
+			if typ == "" && data == nil {
+				continue
+			}
+			e, err := parseEvent(typ, id, retry, data)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, e)
+			typ, id, retry, data = "", "", 0, nil
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			typ = value
+		case "id":
+			id = value
+		case "retry":
+			ms, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid retry %q: %w", value, err)
+			}
+			retry = time.Duration(ms) * time.Millisecond
+		case "data":
+			data = append(data, value)
+		default:
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+	}
+	if typ != "" || data != nil {
+		return nil, fmt.Errorf("event %q not terminated by a blank line", typ)
+	}
+	return events, nil
+}
+
+// readLine reads a line without a length limit, since patched elements are sent on a single line and can be large.
+// Like in the SSE spec, lines end with CRLF, LF, or a lone CR.
+func readLine(br *bufio.Reader) (string, error) {
+	var b strings.Builder
+	for {
+		c, err := br.ReadByte()
+		if err == io.EOF && b.Len() > 0 {
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch c {
+		case '\n':
+			return b.String(), nil
+		case '\r':
+			if next, err := br.Peek(1); err == nil && next[0] == '\n' {
+				_, _ = br.ReadByte()
+			}
+			return b.String(), nil
+		}
+		b.WriteByte(c)
+	}
+}
+
+func parseEvent(typ, id string, retry time.Duration, data []string) (Event, error) {
+	values := map[string][]string{}
+	for _, line := range data {
+		key, value, _ := strings.Cut(line, " ")
+		values[key] = append(values[key], value)
+	}
+
+	switch typ {
+	case datastar.EventTypePatchElements:
+		e := PatchElementsEvent{
+			ID:                id,
+			Retry:             retry,
+			Selector:          strings.Join(values["selector"], " "),
+			Mode:              datastar.PatchModeOuter,
+			UseViewTransition: strings.Join(values["useViewTransition"], "") == "true",
+			Elements:          strings.Join(values["elements"], "\n"),
+		}
+		if mode := strings.Join(values["mode"], ""); mode != "" {
+			e.Mode = datastar.PatchMode(mode)
+		}
+		return e, nil
+
+	case datastar.EventTypePatchSignals:
+		e := PatchSignalsEvent{
+			ID:            id,
+			Retry:         retry,
+			OnlyIfMissing: strings.Join(values["onlyIfMissing"], "") == "true",
+		}
+		if err := json.Unmarshal([]byte(strings.Join(values["signals"], "\n")), &e.Signals); err != nil {
+			return nil, fmt.Errorf("invalid signals: %w", err)
+		}
+		return e, nil
+
+	default:
+		return nil, fmt.Errorf("unknown event type %q", typ)
+	}
+}
+
+// Events parses the events written to the recorder. Fails the test if they can't be parsed.
+func (r *Recorder) Events(t testing.TB) []Event {
+	t.Helper()
+
+	events, err := Parse(strings.NewReader(r.Body.String()))
+	if err != nil {
+		t.Fatalf("failed to parse events: %v", err)
+	}
+	return events
+}
+
+// PatchElementsEvents returns the `datastar-patch-elements` events written to the recorder, in order.
+func (r *Recorder) PatchElementsEvents(t testing.TB) []PatchElementsEvent {
+	t.Helper()
+
+	var events []PatchElementsEvent
+	for _, e := range r.Events(t) {
+		if e, ok := e.(PatchElementsEvent); ok {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// PatchSignalsEvents returns the `datastar-patch-signals` events written to the recorder, in order.
+func (r *Recorder) PatchSignalsEvents(t testing.TB) []PatchSignalsEvent {
+	t.Helper()
+
+	var events []PatchSignalsEvent
+	for _, e := range r.Events(t) {
+		if e, ok := e.(PatchSignalsEvent); ok {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// AssertPatchedSignals asserts that the signals patched by all `datastar-patch-signals` events,
+// merged in order like the client does, equal want.
+// want can be anything that marshals to a JSON object, such as a map or a struct, and is compared as JSON.
+// Signals patched with nil are removed, and events with onlyIfMissing only add signals that don't exist yet.
+func (r *Recorder) AssertPatchedSignals(t testing.TB, want any) {
+	t.Helper()
+
+	got := map[string]any{}
+	for _, e := range r.PatchSignalsEvents(t) {
+		mergeSignals(got, e.Signals, e.OnlyIfMissing)
+	}
+
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("failed to marshal wanted signals: %v", err)
+	}
+	var wantSignals map[string]any
+	if err := json.Unmarshal(wantJSON, &wantSignals); err != nil {
+		t.Fatalf("wanted signals must be a JSON object: %v", err)
+	}
+
+	if !reflect.DeepEqual(wantSignals, got) {
+		gotJSON, _ := json.Marshal(got)
+		t.Errorf("expected patched signals %s but got %s", normalize(wantJSON), gotJSON)
+	}
+}
+
+// AssertPatchedElement asserts that a `datastar-patch-elements` event targets the selector and contains the HTML.
+// An event targets the selector if its selector is the same,
+// or, for events without a selector, if the selector is "#id" and the elements have that ID.
+func (r *Recorder) AssertPatchedElement(t testing.TB, selector, containsHTML string) {
+	t.Helper()
+
+	events := r.PatchElementsEvents(t)
+	for _, e := range events {
+		if targets(e, selector) && strings.Contains(e.Elements, containsHTML) {
+			return
+		}
+	}
+
+	var b strings.Builder
+	for _, e := range events {
+		fmt.Fprintf(&b, "\n\tselector %q, mode %v: %v", e.Selector, e.Mode, e.Elements)
+	}
+	t.Errorf("expected a patched element for selector %q containing %q, but got %v events:%v", selector, containsHTML, len(events), b.String())
+}
+
+func targets(e PatchElementsEvent, selector string) bool {
+	if e.Selector != "" {
+		return e.Selector == selector
+	}
+	id := strings.TrimPrefix(selector, "#")
+	if id == selector {
+		return false
+	}
+	return strings.Contains(e.Elements, `id="`+id+`"`)
+}
+
+// mergeSignals merges the patch into the signals like the client does, where nil values remove signals.
+func mergeSignals(signals, patch map[string]any, onlyIfMissing bool) {
+	for key, value := range patch {
+		if nested, ok := value.(map[string]any); ok {
+			existing, ok := signals[key].(map[string]any)
+			if !ok {
+				if _, exists := signals[key]; exists && onlyIfMissing {
+					continue
+				}
+				existing = map[string]any{}
+				signals[key] = existing
+			}
+			mergeSignals(existing, nested, onlyIfMissing)
+			continue
+		}
+
+		if _, exists := signals[key]; exists && onlyIfMissing {
+			continue
+		}
+		if value == nil {
+			delete(signals, key)
+			continue
+		}
+		signals[key] = value
+	}
+}
+
+// normalize the JSON object, so keys are sorted like in marshalled maps.
+func normalize(b []byte) []byte {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return b
+	}
+	normalized, err := json.Marshal(v)
+	if err != nil {
+		return b
+	}
+	return normalized
+}
@@ -0,0 +1,228 @@
+package datastartest_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	g "maragu.dev/gomponents"
+	. "maragu.dev/gomponents/html"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/datastartest"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("should parse lines longer than the signals size limit", func(t *testing.T) {
+		elements := "<div>" + strings.Repeat("a", 2*data.MaxSignalsSize) + "</div>"
+		events, err := datastartest.Parse(strings.NewReader("event: datastar-patch-elements\ndata: elements " + elements + "\n\n"))
+		assert.NoError(t, err)
+		if len(events) != 1 {
+			t.Fatalf("expected 1 event, got %v", len(events))
+		}
+		assert.EqualString(t, elements, events[0].(datastartest.PatchElementsEvent).Elements)
+	})
+
+	t.Run("should parse lines ending with CRLF or a lone CR", func(t *testing.T) {
+		events, err := datastartest.Parse(strings.NewReader("event: datastar-patch-elements\r\ndata: elements <div>\rdata: elements </div>\r\n\r"))
+		assert.NoError(t, err)
+		if len(events) != 1 {
+			t.Fatalf("expected 1 event, got %v", len(events))
+		}
+		assert.EqualString(t, "<div>\n</div>", events[0].(datastartest.PatchElementsEvent).Elements)
+	})
+
+	t.Run("should treat a lone CR inside a data line as a line end", func(t *testing.T) {
+		_, err := datastartest.Parse(strings.NewReader("event: datastar-patch-elements\ndata: elements <div>a\rb</div>\n\n"))
+		assert.Error(t, err)
+		assert.EqualString(t, `unknown field "b</div>"`, err.Error())
+	})
+
+	t.Run("should parse patch elements and patch signals events", func(t *testing.T) {
+		events, err := datastartest.Parse(strings.NewReader("event: datastar-patch-elements\n" +
+			"id: 1\n" +
+			"retry: 2000\n" +
+			"data: selector #list\n" +
+			"data: mode append\n" +
+			"data: useViewTransition true\n" +
+			"data: elements <li>\n" +
+			"data: elements New</li>\n" +
+			"\n" +
+			": a comment\n" +
+			"event: datastar-patch-signals\n" +
+			"data: onlyIfMissing true\n" +
+			"data: signals {\"foo\":1}\n" +
+			"\n"))
+		assert.NoError(t, err)
+
+		if len(events) != 2 {
+			t.Fatalf("expected 2 events, got %v", len(events))
+		}
+
+		pe, ok := events[0].(datastartest.PatchElementsEvent)
+		if !ok {
+			t.Fatalf("expected PatchElementsEvent, got %T", events[0])
+		}
+		assert.EqualString(t, "1", pe.ID)
+		assert.EqualString(t, "2s", pe.Retry.String())
+		assert.EqualString(t, "#list", pe.Selector)
+		assert.EqualString(t, "append", string(pe.Mode))
+		assert.EqualString(t, "true", fmt.Sprint(pe.UseViewTransition))
+		assert.EqualString(t, "<li>\nNew</li>", pe.Elements)
+
+		ps, ok := events[1].(datastartest.PatchSignalsEvent)
+		if !ok {
+			t.Fatalf("expected PatchSignalsEvent, got %T", events[1])
+		}
+		assert.EqualString(t, "true", fmt.Sprint(ps.OnlyIfMissing))
+		assert.EqualString(t, "map[foo:1]", fmt.Sprint(ps.Signals))
+	})
+
+	t.Run("should default to outer mode", func(t *testing.T) {
+		events, err := datastartest.Parse(strings.NewReader("event: datastar-patch-elements\ndata: elements <div id=\"foo\"></div>\n\n"))
+		assert.NoError(t, err)
+		assert.EqualString(t, "outer", string(events[0].(datastartest.PatchElementsEvent).Mode))
+	})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "unknown event type", body: "event: foo\ndata: bar\n\n"},
+		{name: "unknown field", body: "event: datastar-patch-signals\nfoo: bar\n\n"},
+		{name: "invalid retry", body: "event: datastar-patch-signals\nretry: soon\ndata: signals {}\n\n"},
+		{name: "invalid signals", body: "event: datastar-patch-signals\ndata: signals {\n\n"},
+		{name: "unterminated event", body: "event: datastar-patch-signals\ndata: signals {}\n"},
+	}
+	for _, test := range tests {
+		t.Run("should return an error for "+test.name, func(t *testing.T) {
+			_, err := datastartest.Parse(strings.NewReader(test.body))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRecorder_AssertPatchedSignals(t *testing.T) {
+	t.Run("should pass for signals merged from all events", func(t *testing.T) {
+		rec := record(t, func(sse *data.SSE) {
+			_ = sse.PatchSignals(map[string]any{"foo": 1, "user": map[string]any{"name": "Jane", "age": 42}})
+			_ = sse.PatchElements(Div(ID("foo")))
+			_ = sse.PatchSignals(map[string]any{"user": map[string]any{"age": nil}, "bar": true})
+			_ = sse.PatchSignals(map[string]any{"foo": 2, "baz": "new"}, data.WithOnlyIfMissing())
+		})
+
+		rec.AssertPatchedSignals(t, map[string]any{"foo": 1, "bar": true, "baz": "new", "user": map[string]any{"name": "Jane"}})
+	})
+
+	t.Run("should compare structs as JSON", func(t *testing.T) {
+		rec := record(t, func(sse *data.SSE) {
+			_ = sse.PatchSignals(map[string]any{"count": 3})
+		})
+
+		rec.AssertPatchedSignals(t, struct {
+			Count int `json:"count"`
+		}{Count: 3})
+	})
+
+	t.Run("should fail for different signals", func(t *testing.T) {
+		rec := record(t, func(sse *data.SSE) {
+			_ = sse.PatchSignals(map[string]any{"foo": 1})
+		})
+
+		ft := &fakeT{TB: t}
+		rec.AssertPatchedSignals(ft, map[string]any{"foo": 2})
+		assert.EqualString(t, `expected patched signals {"foo":2} but got {"foo":1}`, ft.message)
+	})
+}
+
+func TestRecorder_AssertPatchedElement(t *testing.T) {
+	t.Run("should pass for an element matched by ID", func(t *testing.T) {
+		rec := record(t, func(sse *data.SSE) {
+			_ = sse.PatchElements(Div(ID("foo"), g.Text("Hello")))
+		})
+
+		rec.AssertPatchedElement(t, "#foo", "Hello")
+	})
+
+	t.Run("should pass for an element matched by selector", func(t *testing.T) {
+		rec := record(t, func(sse *data.SSE) {
+			_ = sse.PatchElements(Li(g.Text("New")), data.WithSelector("#list"), data.WithMode(data.PatchModeAppend))
+		})
+
+		rec.AssertPatchedElement(t, "#list", "<li>New</li>")
+	})
+
+	t.Run("should fail if no element matches", func(t *testing.T) {
+		rec := record(t, func(sse *data.SSE) {
+			_ = sse.PatchElements(Div(ID("foo"), g.Text("Hello")))
+		})
+
+		ft := &fakeT{TB: t}
+		rec.AssertPatchedElement(ft, "#bar", "Hello")
+		assert.EqualString(t, `expected a patched element for selector "#bar" containing "Hello", but got 1 events:`+
+			"\n\t"+`selector "", mode outer: <div id="foo">Hello</div>`, ft.message)
+	})
+}
+
+func TestRecorder_PatchElementsEvents(t *testing.T) {
+	t.Run("should only return patch elements events", func(t *testing.T) {
+		rec := record(t, func(sse *data.SSE) {
+			_ = sse.PatchElements(Div(ID("foo")), data.WithEventID("1"), data.WithRetryDuration(time.Second))
+			_ = sse.PatchSignals(map[string]any{"foo": 1})
+			_ = sse.RemoveElements("#bar")
+		})
+
+		events := rec.PatchElementsEvents(t)
+		assert.EqualString(t, `[{1 1s  outer false <div id="foo"></div>} { 0s #bar remove false }]`, fmt.Sprint(events))
+		assert.EqualString(t, "1", fmt.Sprint(len(rec.PatchSignalsEvents(t))))
+	})
+}
+
+func record(t *testing.T, f func(sse *data.SSE)) *datastartest.Recorder {
+	t.Helper()
+
+	rec := datastartest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	f(data.NewSSE(rec, r))
+	return rec
+}
+
+// fakeT records the message of a failed assertion.
+type fakeT struct {
+	testing.TB
+	message string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.message = fmt.Sprintf(format, args...)
+}
+
+func ExampleRecorder() {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		sse := data.NewSSE(w, r)
+		_ = sse.PatchElements(Div(ID("greeting"), g.Text("Hello")))
+		_ = sse.PatchSignals(map[string]any{"loading": false})
+	}
+
+	rec := datastartest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	events, _ := datastartest.Parse(rec.Body)
+	for _, e := range events {
+		switch e := e.(type) {
+		case datastartest.PatchElementsEvent:
+			fmt.Println(e.Elements)
+		case datastartest.PatchSignalsEvent:
+			fmt.Println(e.Signals)
+		}
+	}
+	// Output:
+	// <div id="greeting">Hello</div>
+	// map[loading:false]
+}
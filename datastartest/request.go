@@ -0,0 +1,79 @@
+package datastartest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+)
+
+// NewRequest returns a new incoming server request like the Datastar client sends for a backend action
+// with the default JSON content type, suitable for passing to an [http.Handler] for testing.
+//
+// The signals can be anything that marshals to a JSON object, and nil means no signals.
+// For GET requests, they are sent in the `datastar` query parameter, which is added to any existing query.
+// For all other methods, they are sent in the request body.
+//
+// Panics on error, like [httptest.NewRequest].
+func NewRequest(method, target string, signals any) *http.Request {
+	if signals == nil {
+		signals = map[string]any{}
+	}
+	// Encode like JSON.stringify in the browser, which doesn't escape HTML characters
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(signals); err != nil {
+		panic("failed to marshal signals: " + err.Error())
+	}
+	body := strings.TrimSuffix(b.String(), "\n")
+
+	var r *http.Request
+	if method == http.MethodGet {
+		r = httptest.NewRequest(method, target, nil)
+		query := r.URL.Query()
+		query.Set("datastar", body)
+		r.URL.RawQuery = query.Encode()
+		r.RequestURI = r.URL.RequestURI()
+	} else {
+		r = httptest.NewRequest(method, target, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	setHeaders(r)
+	return r
+}
+
+// NewFormRequest is like [NewRequest], but for backend actions with the form content type,
+// which send the values of a form instead of the signals.
+// For GET requests, the values are added to the query. For all other methods, they are sent url-encoded in the request body.
+//
+// Panics on error, like [httptest.NewRequest].
+func NewFormRequest(method, target string, values url.Values) *http.Request {
+	var r *http.Request
+	if method == http.MethodGet {
+		r = httptest.NewRequest(method, target, nil)
+		query := r.URL.Query()
+		for key, vs := range values {
+			for _, v := range vs {
+				query.Add(key, v)
+			}
+		}
+		r.URL.RawQuery = query.Encode()
+		r.RequestURI = r.URL.RequestURI()
+	} else {
+		r = httptest.NewRequest(method, target, strings.NewReader(values.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	setHeaders(r)
+	return r
+}
+
+// setHeaders that the Datastar client sends with every backend action.
+func setHeaders(r *http.Request) {
+	r.Header.Set("Datastar-Request", "true")
+	r.Header.Set("Accept", "text/event-stream, text/html, application/json")
+}
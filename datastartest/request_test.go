@@ -0,0 +1,89 @@
+package datastartest_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/datastartest"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+type testSignals struct {
+	Foo int    `json:"foo"`
+	Bar string `json:"bar"`
+}
+
+func TestNewRequest(t *testing.T) {
+	t.Run("should send signals in the query for GET requests", func(t *testing.T) {
+		r := datastartest.NewRequest(http.MethodGet, "/search?page=2", testSignals{Foo: 1, Bar: "a&b"})
+
+		assert.EqualString(t, "2", r.URL.Query().Get("page"))
+		assert.EqualString(t, `{"foo":1,"bar":"a&b"}`, r.URL.Query().Get("datastar"))
+		assert.EqualString(t, r.URL.RequestURI(), r.RequestURI)
+		assert.EqualString(t, "true", r.Header.Get("Datastar-Request"))
+		assert.EqualString(t, "text/event-stream, text/html, application/json", r.Header.Get("Accept"))
+		assert.EqualString(t, "", r.Header.Get("Content-Type"))
+
+		var signals testSignals
+		assert.NoError(t, data.ReadSignals(r, &signals))
+		assert.EqualString(t, "1 a&b", fmt.Sprint(signals.Foo, " ", signals.Bar))
+	})
+
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		t.Run("should send signals in the body for "+method+" requests", func(t *testing.T) {
+			r := datastartest.NewRequest(method, "/todos", map[string]any{"foo": 2, "bar": "b"})
+
+			assert.EqualString(t, method, r.Method)
+			assert.EqualString(t, "", r.URL.Query().Get("datastar"))
+			assert.EqualString(t, "application/json", r.Header.Get("Content-Type"))
+			assert.EqualString(t, "true", r.Header.Get("Datastar-Request"))
+
+			var signals testSignals
+			assert.NoError(t, data.ReadSignals(r, &signals))
+			assert.EqualString(t, "2 b", fmt.Sprint(signals.Foo, " ", signals.Bar))
+		})
+	}
+
+	t.Run("should send an empty object for nil signals", func(t *testing.T) {
+		r := datastartest.NewRequest(http.MethodPost, "/", nil)
+
+		b, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.EqualString(t, "{}", string(b))
+	})
+
+	t.Run("should panic if the signals can't be marshalled", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		datastartest.NewRequest(http.MethodPost, "/", make(chan int))
+	})
+}
+
+func TestNewFormRequest(t *testing.T) {
+	t.Run("should send form values in the query for GET requests", func(t *testing.T) {
+		r := datastartest.NewFormRequest(http.MethodGet, "/search?page=2", url.Values{"q": {"foo bar"}})
+
+		assert.EqualString(t, "foo bar", r.URL.Query().Get("q"))
+		assert.EqualString(t, "2", r.URL.Query().Get("page"))
+		assert.EqualString(t, "true", r.Header.Get("Datastar-Request"))
+		assert.NoError(t, r.ParseForm())
+		assert.EqualString(t, "foo bar", r.Form.Get("q"))
+	})
+
+	t.Run("should send form values in the body for POST requests", func(t *testing.T) {
+		r := datastartest.NewFormRequest(http.MethodPost, "/todos", url.Values{"title": {"Buy milk"}, "tags": {"a", "b"}})
+
+		assert.EqualString(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		assert.EqualString(t, "true", r.Header.Get("Datastar-Request"))
+		assert.NoError(t, r.ParseForm())
+		assert.EqualString(t, "Buy milk", r.PostForm.Get("title"))
+		assert.EqualString(t, "[a b]", fmt.Sprint(r.PostForm["tags"]))
+	})
+}
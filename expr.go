@@ -0,0 +1,254 @@
+package datastar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expr is a JavaScript expression, built with the expression builders below.
+// Leaves are created from signals with [Sig], from Go values with [Str] and [Lit], which are always escaped,
+// and from trusted code with [Raw], which is used as is.
+// Operands are parenthesized based on operator precedence when the expression is built, so the result keeps the
+// intended structure no matter what the operands are.
+//
+// Attributes that take an expression, such as [Show], [Effect], and [On], accept an Expr directly:
+//
+//	data.Show(data.And(data.Sig("open"), data.Not(data.Sig("loading"))))
+//
+// <div data-show="$open && !$loading"></div>
+//
+// Use [Expr.String] for the values of [Class], [Style], and [Computed], and anywhere else code is a string.
+type Expr struct {
+	code string
+	prec precedence
+}
+
+// String returns the JavaScript code of the expression.
+func (e Expr) String() string {
+	return e.code
+}
+
+// Expression is an expression given to an attribute: either JavaScript code as a string, or an [Expr].
+type Expression interface {
+	string | Expr
+}
+
+// exprCode returns the JavaScript code of the expression.
+func exprCode[E Expression](expression E) string {
+	if e, ok := any(expression).(Expr); ok {
+		return e.code
+	}
+	return any(expression).(string)
+}
+
+// precedence of an expression, from loosest to tightest binding.
+type precedence int
+
+const (
+	precSeq precedence = iota
+	precAssign
+	precCond
+	precNullish
+	precOr
+	precAnd
+	precEquality
+	precRelational
+	precAdditive
+	precMultiplicative
+	precUnary
+	precCall
+)
+
+var binaryPrecedences = map[string]precedence{
+	"??":  precNullish,
+	"||":  precOr,
+	"&&":  precAnd,
+	"===": precEquality,
+	"!==": precEquality,
+	"==":  precEquality,
+	"!=":  precEquality,
+	"<":   precRelational,
+	"<=":  precRelational,
+	">":   precRelational,
+	">=":  precRelational,
+	"+":   precAdditive,
+	"-":   precAdditive,
+	"*":   precMultiplicative,
+	"/":   precMultiplicative,
+	"%":   precMultiplicative,
+}
+
+// Raw returns an expression with the code used as is. Only use it for trusted code, never for user data.
+// The code is always parenthesized when used as an operand.
+func Raw(code string) Expr {
+	return Expr{code: code, prec: precSeq}
+}
+
+// Sig returns an expression referring to the signal with the given name, such as "$foo".
+// Panics if the name is not a valid signal name. See [ValidateSignalName].
+func Sig(name string) Expr {
+	mustValidateSignalName(name)
+	return Expr{code: "$" + name, prec: precCall}
+}
+
+// Str returns a string literal expression, with quotes, backslashes, line terminators and "<" escaped.
+// Use it for any string that is not a trusted expression, such as user data.
+func Str(s string) Expr {
+	return Expr{code: quote(s), prec: precCall}
+}
+
+// Lit returns a literal expression for the Go value, encoded with [JS].
+// Panics if the value can't be encoded.
+func Lit(v any) Expr {
+	code := JS(v)
+	if strings.HasPrefix(code, "-") {
+		return Expr{code: code, prec: precUnary}
+	}
+	return Expr{code: code, prec: precCall}
+}
+
+// Not returns the logical negation of the expression: !x.
+func Not(x Expr) Expr {
+	return Expr{code: "!" + x.operand(precUnary), prec: precUnary}
+}
+
+// And returns the logical and of the expressions: x && y.
+// Panics if there are no expressions.
+func And(xs ...Expr) Expr {
+	return fold("&&", xs)
+}
+
+// Or returns the logical or of the expressions: x || y.
+// Panics if there are no expressions.
+func Or(xs ...Expr) Expr {
+	return fold("||", xs)
+}
+
+// Eq compares the expressions for strict equality: x === y.
+func Eq(x, y Expr) Expr {
+	return Binary(x, "===", y)
+}
+
+// NotEq compares the expressions for strict inequality: x !== y.
+func NotEq(x, y Expr) Expr {
+	return Binary(x, "!==", y)
+}
+
+// Lt returns x < y.
+func Lt(x, y Expr) Expr {
+	return Binary(x, "<", y)
+}
+
+// Lte returns x <= y.
+func Lte(x, y Expr) Expr {
+	return Binary(x, "<=", y)
+}
+
+// Gt returns x > y.
+func Gt(x, y Expr) Expr {
+	return Binary(x, ">", y)
+}
+
+// Gte returns x >= y.
+func Gte(x, y Expr) Expr {
+	return Binary(x, ">=", y)
+}
+
+// Add returns x + y.
+func Add(x, y Expr) Expr {
+	return Binary(x, "+", y)
+}
+
+// Sub returns x - y.
+func Sub(x, y Expr) Expr {
+	return Binary(x, "-", y)
+}
+
+// Binary returns an expression with the binary operator applied to x and y, such as "$a * 2".
+// The operator is one of the logical, comparison, and arithmetic operators:
+// ??, ||, &&, ===, !==, ==, !=, <, <=, >, >=, +, -, *, /, and %.
+// Panics if the operator is not supported.
+func Binary(x Expr, operator string, y Expr) Expr {
+	prec, ok := binaryPrecedences[operator]
+	if !ok {
+		panic(fmt.Sprintf("unsupported binary operator: %v", operator))
+	}
+
+	// Operands are left-associative, so the right operand must bind tighter than the operator.
+	left, right := x.operand(prec), y.operand(prec+1)
+	// JavaScript doesn't allow mixing ?? with && or || without parentheses.
+	if operator == "??" {
+		left, right = x.operand(precEquality), y.operand(precEquality)
+	}
+	return Expr{code: left + " " + operator + " " + right, prec: prec}
+}
+
+// Cond returns a ternary expression: test ? then : otherwise.
+// Nested ternary expressions are only left without parentheses in the otherwise branch, where they read as a chain.
+func Cond(test, then, otherwise Expr) Expr {
+	return Expr{
+		code: test.operand(precNullish) + " ? " + then.operand(precNullish) + " : " + otherwise.operand(precAssign),
+		prec: precCond,
+	}
+}
+
+// Call returns a function call expression: fn(args...).
+// The function is used as is, so it must be trusted code, such as "console.log".
+func Call(fn string, args ...Expr) Expr {
+	return Expr{code: fn + "(" + joinArgs(args) + ")", prec: precCall}
+}
+
+// Action returns an action expression: @name(args...).
+// See [Get], [Post], [Put], [Patch], and [Delete] for the backend actions.
+func Action(name string, args ...Expr) Expr {
+	return Call("@"+name, args...)
+}
+
+// Assign returns an expression that assigns the value to the target: target = value.
+func Assign(target, value Expr) Expr {
+	return Expr{code: target.operand(precCall) + " = " + value.operand(precAssign), prec: precAssign}
+}
+
+// Seq returns the expressions joined with the comma operator: x, y.
+// They are evaluated in order, and the result is the value of the last one.
+func Seq(xs ...Expr) Expr {
+	return Expr{code: joinArgs(xs), prec: precSeq}
+}
+
+// operand returns the code of the expression, parenthesized if it binds looser than the given precedence.
+func (e Expr) operand(prec precedence) string {
+	if e.prec < prec {
+		return "(" + e.code + ")"
+	}
+	return e.code
+}
+
+// fold the expressions with the left-associative binary operator.
+func fold(operator string, xs []Expr) Expr {
+	if len(xs) == 0 {
+		panic(fmt.Sprintf("%v needs at least one expression", operator))
+	}
+	e := xs[0]
+	for _, x := range xs[1:] {
+		e = Binary(e, operator, x)
+	}
+	return e
+}
+
+// property returns an expression accessing the property of x: x.name.
+func property(x Expr, name string) Expr {
+	return Expr{code: x.operand(precCall) + "." + name, prec: precCall}
+}
+
+// method returns an expression calling the method of x: x.name(args...).
+func method(x Expr, name string, args ...Expr) Expr {
+	return Call(property(x, name).code, args...)
+}
+
+func joinArgs(args []Expr) string {
+	codes := make([]string, len(args))
+	for i, arg := range args {
+		codes[i] = arg.operand(precAssign)
+	}
+	return strings.Join(codes, ", ")
+}
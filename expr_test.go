@@ -0,0 +1,104 @@
+package datastar_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "maragu.dev/gomponents/html"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+func TestExpressions(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression data.Expr
+		expected   string
+	}{
+		{name: "Sig", expression: data.Sig("foo"), expected: `$foo`},
+		{name: "Sig with path", expression: data.Sig("user.name"), expected: `$user.name`},
+		{name: "Str", expression: data.Str("it's"), expected: `'it\'s'`},
+		{name: "Str with script", expression: data.Str("</script><script>alert(1)</script>"), expected: `'\x3C/script>\x3Cscript>alert(1)\x3C/script>'`},
+		{name: "Str with line terminators", expression: data.Str("a\nb\r\u2028\u2029"), expected: `'a\nb\r\u2028\u2029'`},
+		{name: "Lit", expression: data.Lit(1), expected: `1`},
+		{name: "Lit with string", expression: data.Lit("a && b"), expected: `'a && b'`},
+		{name: "Lit with map", expression: data.Lit(map[string]any{"a": true}), expected: `{a: true}`},
+		{name: "Raw", expression: data.Raw("$a.b"), expected: `$a.b`},
+		{name: "Not", expression: data.Not(data.Sig("loading")), expected: `!$loading`},
+		{name: "Not with binary", expression: data.Not(data.Eq(data.Sig("a"), data.Lit(1))), expected: `!($a === 1)`},
+		{name: "Not with call", expression: data.Not(data.Call("isValid", data.Sig("a"))), expected: `!isValid($a)`},
+		{name: "Not with raw", expression: data.Not(data.Raw("$a")), expected: `!($a)`},
+		{name: "And", expression: data.And(data.Sig("a"), data.Not(data.Sig("b")), data.Sig("c")), expected: `$a && !$b && $c`},
+		{name: "And with one expression", expression: data.And(data.Sig("a")), expected: `$a`},
+		{name: "Or in And", expression: data.And(data.Or(data.Sig("a"), data.Sig("b")), data.Sig("c")), expected: `($a || $b) && $c`},
+		{name: "And in Or", expression: data.Or(data.And(data.Sig("a"), data.Sig("b")), data.Sig("c")), expected: `$a && $b || $c`},
+		{name: "Eq", expression: data.Eq(data.Sig("name"), data.Str("foo")), expected: `$name === 'foo'`},
+		{name: "Eq with string containing operators", expression: data.Eq(data.Sig("name"), data.Str("a && b")), expected: `$name === 'a && b'`},
+		{name: "Eq with raw", expression: data.Eq(data.Sig("name"), data.Raw("'a' || $b")), expected: `$name === ('a' || $b)`},
+		{name: "NotEq", expression: data.NotEq(data.Sig("a"), data.Lit(nil)), expected: `$a !== null`},
+		{name: "Lt", expression: data.Lt(data.Sig("a"), data.Lit(1)), expected: `$a < 1`},
+		{name: "Lte", expression: data.Lte(data.Sig("a"), data.Lit(1)), expected: `$a <= 1`},
+		{name: "Gt", expression: data.Gt(data.Sig("a"), data.Lit(1)), expected: `$a > 1`},
+		{name: "Gte", expression: data.Gte(data.Sig("a"), data.Lit(1)), expected: `$a >= 1`},
+		{name: "Add in Eq", expression: data.Eq(data.Add(data.Sig("a"), data.Lit(1)), data.Lit(2)), expected: `$a + 1 === 2`},
+		{name: "Add with negative number", expression: data.Add(data.Sig("a"), data.Lit(-1)), expected: `$a + -1`},
+		{name: "Sub", expression: data.Sub(data.Sig("a"), data.Sub(data.Sig("b"), data.Lit(1))), expected: `$a - ($b - 1)`},
+		{name: "Sub left-associative", expression: data.Sub(data.Sub(data.Sig("a"), data.Sig("b")), data.Lit(1)), expected: `$a - $b - 1`},
+		{name: "Binary", expression: data.Binary(data.Sig("price"), "*", data.Sig("quantity")), expected: `$price * $quantity`},
+		{name: "Binary with Add in multiplication", expression: data.Binary(data.Add(data.Sig("a"), data.Sig("b")), "*", data.Lit(2)), expected: `($a + $b) * 2`},
+		{name: "Binary nullish with Or", expression: data.Binary(data.Or(data.Sig("a"), data.Sig("b")), "??", data.Str("")), expected: `($a || $b) ?? ''`},
+		{name: "Or with nullish", expression: data.Or(data.Sig("a"), data.Binary(data.Sig("b"), "??", data.Sig("c"))), expected: `$a || ($b ?? $c)`},
+		{name: "Cond", expression: data.Cond(data.Sig("hiding"), data.Str("none"), data.Str("flex")), expected: `$hiding ? 'none' : 'flex'`},
+		{name: "Cond with binary test", expression: data.Cond(data.Gt(data.Sig("a"), data.Lit(1)), data.Str("many"), data.Str("one")), expected: `$a > 1 ? 'many' : 'one'`},
+		{name: "Cond in Cond test", expression: data.Cond(data.Cond(data.Sig("a"), data.Sig("b"), data.Sig("c")), data.Lit(1), data.Lit(2)), expected: `($a ? $b : $c) ? 1 : 2`},
+		{name: "Cond in Cond then", expression: data.Cond(data.Sig("a"), data.Cond(data.Sig("b"), data.Lit(1), data.Lit(2)), data.Lit(3)), expected: `$a ? ($b ? 1 : 2) : 3`},
+		{name: "Cond in Cond otherwise", expression: data.Cond(data.Sig("a"), data.Lit(1), data.Cond(data.Sig("b"), data.Lit(2), data.Lit(3))), expected: `$a ? 1 : $b ? 2 : 3`},
+		{name: "Call", expression: data.Call("console.log", data.Str("hi"), data.Sig("a")), expected: `console.log('hi', $a)`},
+		{name: "Call with sequence", expression: data.Call("console.log", data.Seq(data.Sig("a"), data.Sig("b"))), expected: `console.log(($a, $b))`},
+		{name: "Not with sequence", expression: data.Not(data.Seq(data.Sig("a"), data.Sig("b"))), expected: `!($a, $b)`},
+		{name: "Action", expression: data.Action("setAll", data.Lit(true)), expected: `@setAll(true)`},
+		{name: "Assign", expression: data.Assign(data.Sig("count"), data.Add(data.Sig("count"), data.Lit(1))), expected: `$count = $count + 1`},
+		{name: "Seq", expression: data.Seq(data.Assign(data.Sig("a"), data.Lit(1)), data.Raw(data.Get("/endpoint"))), expected: `$a = 1, (@get('/endpoint'))`},
+		{name: "Seq in Seq", expression: data.Seq(data.Sig("a"), data.Seq(data.Sig("b"), data.Sig("c"))), expected: `$a, ($b, $c)`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualString(t, test.expected, test.expression.String())
+		})
+	}
+
+	t.Run("should escape user input in literals", func(t *testing.T) {
+		userInput := "' || alert(1) || '"
+		assert.EqualString(t, `$name === '\' || alert(1) || \''`, data.Eq(data.Sig("name"), data.Str(userInput)).String())
+	})
+
+	t.Run("should panic on unsupported binary operator", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected panic")
+			}
+		}()
+		data.Binary(data.Sig("a"), "; alert(1);", data.Sig("b"))
+	})
+
+	t.Run("should panic on And without expressions", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected panic")
+			}
+		}()
+		data.And()
+	})
+}
+
+func ExampleNot() {
+	fmt.Print(Div(data.Show(data.Not(data.Sig("loading")))))
+	// Output: <div data-show="!$loading"></div>
+}
+
+func ExampleCond() {
+	fmt.Print(Div(data.Style("display", data.Cond(data.Sig("hiding"), data.Str("none"), data.Str("flex")).String())))
+	// Output: <div data-style="{display: $hiding ? &#39;none&#39; : &#39;flex&#39;}"></div>
+}
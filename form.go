@@ -0,0 +1,538 @@
+package datastar
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	g "maragu.dev/gomponents"
+	"maragu.dev/gomponents/html"
+)
+
+// Rule is a validation rule for a form [Field].
+// Each rule is both rendered as an HTML constraint attribute and a Datastar expression in the browser,
+// and checked in Go on the server, so the two always agree.
+//
+// Rules other than [Required] only apply to non-empty values, like HTML constraint validation.
+type Rule struct {
+	attr    g.Node
+	message string
+	// test returns an expression that is true if the string expression s is invalid
+	test func(s Expr) Expr
+	// valid reports whether the non-empty value s is valid
+	valid func(s string) bool
+	empty bool
+}
+
+// Required values must not be empty or only whitespace.
+// If the message is empty, a default message is used.
+func Required(message string) Rule {
+	if message == "" {
+		message = "This field is required."
+	}
+	return Rule{
+		attr:    html.Required(),
+		message: message,
+		test: func(s Expr) Expr {
+			return Eq(method(s, "trim"), Str(""))
+		},
+		valid: func(s string) bool {
+			return strings.TrimSpace(s) != ""
+		},
+		empty: true,
+	}
+}
+
+// MinLength values must have at least n characters, counted in UTF-16 code units like in the browser.
+// If the message is empty, a default message is used.
+// Panics if n is negative.
+func MinLength(n int, message string) Rule {
+	if n < 0 {
+		panic(fmt.Sprintf("min length must not be negative, but is: %v", n))
+	}
+	if message == "" {
+		message = fmt.Sprintf("Must be at least %v characters.", n)
+	}
+	return Rule{
+		attr:    html.MinLength(strconv.Itoa(n)),
+		message: message,
+		test: func(s Expr) Expr {
+			return Lt(property(s, "length"), Lit(n))
+		},
+		valid: func(s string) bool {
+			return jsLength(s) >= n
+		},
+	}
+}
+
+// MaxLength values must have at most n characters, counted in UTF-16 code units like in the browser.
+// If the message is empty, a default message is used.
+// Panics if n is negative.
+func MaxLength(n int, message string) Rule {
+	if n < 0 {
+		panic(fmt.Sprintf("max length must not be negative, but is: %v", n))
+	}
+	if message == "" {
+		message = fmt.Sprintf("Must be at most %v characters.", n)
+	}
+	return Rule{
+		attr:    html.MaxLength(strconv.Itoa(n)),
+		message: message,
+		test: func(s Expr) Expr {
+			return Gt(property(s, "length"), Lit(n))
+		},
+		valid: func(s string) bool {
+			return jsLength(s) <= n
+		},
+	}
+}
+
+// Pattern values must match the regular expression in full, like the HTML pattern attribute.
+// The pattern uses Go syntax (see [regexp/syntax]), and is converted to an equivalent JavaScript pattern for the pattern attribute
+// and the check in the browser, so both sides match the same values, even where the two dialects differ,
+// such as for the dot, Unicode classes like \pL, and flags like (?i).
+// If the message is empty, a default message is used.
+// Panics if the pattern is not a valid Go regular expression, or uses multi-line mode, which has no equivalent in the pattern attribute.
+func Pattern(pattern, message string) Rule {
+	re := regexp.MustCompile("^(?:" + pattern + ")$")
+	js := toJSPattern(pattern)
+	if message == "" {
+		message = "Must match the requested format."
+	}
+	return Rule{
+		attr:    html.Pattern(js),
+		message: message,
+		test: func(s Expr) Expr {
+			return Not(method(Call("new RegExp", Str("^(?:"+js+")$"), Str("u")), "test", s))
+		},
+		valid: re.MatchString,
+	}
+}
+
+// toJSPattern converts the Go regular expression to a JavaScript one that matches the same strings,
+// valid with both the u flag and the v flag used by the pattern attribute.
+func toJSPattern(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		panic(err.Error())
+	}
+	var b strings.Builder
+	writeJSPattern(&b, re)
+	return b.String()
+}
+
+func writeJSPattern(b *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		b.WriteString("[]")
+	case syntax.OpEmptyMatch:
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 {
+				writeJSFoldedRune(b, r)
+				continue
+			}
+			writeJSRune(b, r)
+		}
+	case syntax.OpCharClass:
+		writeJSClass(b, re.Rune)
+	case syntax.OpAnyCharNotNL:
+		b.WriteString(`[^\n]`)
+	case syntax.OpAnyChar:
+		b.WriteString(`[^]`)
+	case syntax.OpBeginText:
+		b.WriteString("^")
+	case syntax.OpEndText:
+		b.WriteString("$")
+	case syntax.OpBeginLine, syntax.OpEndLine:
+		panic("pattern must not use multi-line mode")
+	case syntax.OpWordBoundary:
+		b.WriteString(`\b`)
+	case syntax.OpNoWordBoundary:
+		b.WriteString(`\B`)
+	case syntax.OpCapture:
+		b.WriteString("(?:")
+		writeJSPattern(b, re.Sub[0])
+		b.WriteString(")")
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		sub := re.Sub[0]
+		if isJSAtom(sub) {
+			writeJSPattern(b, sub)
+		} else {
+			b.WriteString("(?:")
+			writeJSPattern(b, sub)
+			b.WriteString(")")
+		}
+		switch re.Op {
+		case syntax.OpStar:
+			b.WriteString("*")
+		case syntax.OpPlus:
+			b.WriteString("+")
+		case syntax.OpQuest:
+			b.WriteString("?")
+		default:
+			switch {
+			case re.Max == -1:
+				fmt.Fprintf(b, "{%v,}", re.Min)
+			case re.Min == re.Max:
+				fmt.Fprintf(b, "{%v}", re.Min)
+			default:
+				fmt.Fprintf(b, "{%v,%v}", re.Min, re.Max)
+			}
+		}
+		if re.Flags&syntax.NonGreedy != 0 {
+			b.WriteString("?")
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpAlternate {
+				b.WriteString("(?:")
+				writeJSPattern(b, sub)
+				b.WriteString(")")
+				continue
+			}
+			writeJSPattern(b, sub)
+		}
+	case syntax.OpAlternate:
+		for i, sub := range re.Sub {
+			if i > 0 {
+				b.WriteString("|")
+			}
+			writeJSPattern(b, sub)
+		}
+	default:
+		panic(fmt.Sprintf("unsupported regular expression operator: %v", re.Op))
+	}
+}
+
+// isJSAtom reports whether the regular expression can be quantified without grouping it.
+func isJSAtom(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune) == 1
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL, syntax.OpCapture, syntax.OpNoMatch:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeJSRune outside a character class, escaping syntax characters and anything that isn't printable ASCII.
+func writeJSRune(b *strings.Builder, r rune) {
+	switch {
+	case strings.ContainsRune(`^$\.*+?()[]{}|/`, r):
+		b.WriteByte('\\')
+		b.WriteRune(r)
+	case r >= ' ' && r < 0x7f:
+		b.WriteRune(r)
+	default:
+		writeJSRuneEscape(b, r)
+	}
+}
+
+// writeJSFoldedRune as a character class of all its case variants, like the (?i) flag does.
+func writeJSFoldedRune(b *strings.Builder, r rune) {
+	variants := []rune{r}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		variants = append(variants, f)
+	}
+	if len(variants) == 1 {
+		writeJSRune(b, r)
+		return
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i] < variants[j] })
+	b.WriteString("[")
+	for _, v := range variants {
+		writeJSClassRune(b, v)
+	}
+	b.WriteString("]")
+}
+
+// writeJSClass from the sorted rune ranges of a Go character class, negated if that's shorter.
+func writeJSClass(b *strings.Builder, ranges []rune) {
+	b.WriteString("[")
+	if len(ranges) > 0 && ranges[0] == 0 && ranges[len(ranges)-1] == unicode.MaxRune {
+		b.WriteString("^")
+		var negated []rune
+		for i := 1; i < len(ranges)-1; i += 2 {
+			negated = append(negated, ranges[i]+1, ranges[i+1]-1)
+		}
+		ranges = negated
+	}
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		writeJSClassRune(b, lo)
+		switch {
+		case hi == lo:
+		case hi == lo+1:
+			writeJSClassRune(b, hi)
+		default:
+			b.WriteString("-")
+			writeJSClassRune(b, hi)
+		}
+	}
+	b.WriteString("]")
+}
+
+// writeJSClassRune inside a character class, escaping anything that isn't an ASCII letter, digit, or underscore,
+// since the v flag reserves most punctuation in classes.
+func writeJSClassRune(b *strings.Builder, r rune) {
+	if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+		b.WriteRune(r)
+		return
+	}
+	writeJSRuneEscape(b, r)
+}
+
+func writeJSRuneEscape(b *strings.Builder, r rune) {
+	switch r {
+	case '\n':
+		b.WriteString(`\n`)
+	case '\r':
+		b.WriteString(`\r`)
+	case '\t':
+		b.WriteString(`\t`)
+	default:
+		fmt.Fprintf(b, `\u{%x}`, r)
+	}
+}
+
+// Range values must be numbers between min and max, inclusive.
+// The min and max attributes only apply to number-like inputs in the browser, such as type number and range,
+// but the check in the [Field] expression applies to all inputs.
+// If the message is empty, a default message is used.
+// Panics if min is greater than max.
+func Range(min, max float64, message string) Rule {
+	if min > max {
+		panic(fmt.Sprintf("range min must not be greater than max, but is: %v > %v", min, max))
+	}
+	minJS, maxJS := JS(min), JS(max)
+	if message == "" {
+		message = fmt.Sprintf("Must be between %v and %v.", minJS, maxJS)
+	}
+	return Rule{
+		attr:    g.Group{html.Min(minJS), html.Max(maxJS)},
+		message: message,
+		test: func(s Expr) Expr {
+			n := Call("Number", s)
+			return Or(Call("isNaN", n), Lt(n, Lit(min)), Gt(n, Lit(max)))
+		},
+		valid: func(s string) bool {
+			n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			return err == nil && n >= min && n <= max
+		},
+	}
+}
+
+// Field is a form field bound to a signal, with validation rules.
+type Field struct {
+	name  string
+	rules []Rule
+}
+
+// NewField for the signal with the given name and validation rules. Rules are checked in order, and the first failing one wins.
+// Panics if the name is not a valid signal name. See [ValidateSignalName].
+func NewField(name string, rules ...Rule) Field {
+	mustValidateSignalName(name)
+	return Field{name: name, rules: rules}
+}
+
+// Name of the signal the field is bound to.
+func (f Field) Name() string {
+	return f.name
+}
+
+// ErrorSignal is the name of the signal holding the error message of the field, which is the field name with "Error" appended,
+// such as "emailError" for "email". It is the empty string when the field is valid.
+func (f Field) ErrorSignal() string {
+	return f.name + "Error"
+}
+
+// TouchedSignal is the name of the signal that is true once the user has interacted with the field, which is the field name
+// with "Touched" appended, such as "emailTouched" for "email". Error messages are only shown for touched fields,
+// so the form doesn't start out full of errors.
+func (f Field) TouchedSignal() string {
+	return f.name + "Touched"
+}
+
+// Attrs returns the attributes for the input element of the field:
+//
+//   - [Bind] to the field signal,
+//   - the HTML constraint attributes for the rules, such as required and minlength,
+//   - [Signals] declaring the error and touched signals, if they don't exist yet,
+//   - an [On] blur handler that marks the field as touched,
+//   - an [Effect] that sets the error signal to the message of the first failing rule once the field is touched,
+//     so the message is only shown after the user has interacted with the field, and
+//   - [CustomValidity] from the rules, or the error signal if the rules pass, so the browser blocks submitting the form
+//     while the field is invalid, whether it's touched or not.
+//
+// For example:
+//
+//	field := data.NewField("email", data.Required(""), data.MaxLength(100, ""))
+//	Input(Type("email"), field.Attrs())
+//	Span(data.Text(data.Sig(field.ErrorSignal())))
+func (f Field) Attrs() g.Node {
+	nodes := g.Group{Bind(f.name)}
+	for _, rule := range f.rules {
+		nodes = append(nodes, rule.attr)
+	}
+
+	signals := map[string]any{}
+	setPath(signals, f.ErrorSignal(), "")
+	setPath(signals, f.TouchedSignal(), false)
+
+	return append(nodes,
+		Signals(signals, ModifierIfMissing),
+		On("blur", Assign(Sig(f.TouchedSignal()), Lit(true))),
+		Effect(Assign(Sig(f.ErrorSignal()), Cond(Sig(f.TouchedSignal()), f.Expr(), Str("")))),
+		CustomValidity(Or(f.Expr(), Sig(f.ErrorSignal()))),
+	)
+}
+
+// Expr returns an expression that evaluates to the message of the first failing rule, or the empty string if the field is valid.
+func (f Field) Expr() Expr {
+	s := Call("String", Binary(Sig(f.name), "??", Str("")))
+	expr := Str("")
+	for i := len(f.rules) - 1; i >= 0; i-- {
+		rule := f.rules[i]
+		test := rule.test(s)
+		if !rule.empty {
+			test = And(NotEq(s, Str("")), test)
+		}
+		expr = Cond(test, Str(rule.message), expr)
+	}
+	return expr
+}
+
+// Check the value against the rules, returning the message of the first failing rule, or the empty string if it is valid.
+// The value is converted to a string like the browser does, so nil is empty, and numbers and booleans are formatted.
+func (f Field) Check(value any) string {
+	s := formValueString(value)
+	for _, rule := range f.rules {
+		if s == "" && !rule.empty {
+			continue
+		}
+		if !rule.valid(s) {
+			return rule.message
+		}
+	}
+	return ""
+}
+
+// ValidationErrors maps field names to error messages. See [ValidateFields].
+type ValidationErrors map[string]string
+
+func (e ValidationErrors) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, len(names))
+	for i, name := range names {
+		messages[i] = name + ": " + e[name]
+	}
+	return "invalid fields: " + strings.Join(messages, ", ")
+}
+
+// Signals returns the error signals of the fields, with the error message for invalid fields and the empty string for valid ones,
+// ready to send with [SSE.PatchSignals]. All fields are marked as touched, so the errors keep updating in the browser.
+func (e ValidationErrors) Signals(fields ...Field) map[string]any {
+	signals := map[string]any{}
+	for _, f := range fields {
+		setPath(signals, f.ErrorSignal(), e[f.name])
+		setPath(signals, f.TouchedSignal(), true)
+	}
+	return signals
+}
+
+// ValidateFields checks the values of the fields in v with the same rules used in the browser,
+// such as signals decoded with [ReadSignals].
+// v can be a struct, with field names taken from `json` struct tags like [SignalsFrom], or a map[string]any.
+// Nested signals are looked up by the dot-separated field name.
+// Returns [ValidationErrors] if any field is invalid, and nil otherwise.
+func ValidateFields(v any, fields ...Field) error {
+	errs := ValidationErrors{}
+	for _, f := range fields {
+		if message := f.Check(lookupPath(reflect.ValueOf(v), f.name)); message != "" {
+			errs[f.name] = message
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// lookupPath returns the value at the dot-separated path in v, or nil if there is none.
+func lookupPath(v reflect.Value, path string) any {
+	for _, segment := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return nil
+			}
+			v = v.MapIndex(reflect.ValueOf(segment).Convert(v.Type().Key()))
+		case reflect.Struct:
+			found := false
+			for _, field := range signalFields(v.Type()) {
+				if field.name == segment {
+					v, found = fieldByIndex(v, field.index)
+					break
+				}
+			}
+			if !found {
+				return nil
+			}
+		default:
+			return nil
+		}
+
+		if !v.IsValid() {
+			return nil
+		}
+	}
+
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// formValueString converts the value to a string the same way the expressions from [Field.Expr] do in JavaScript.
+func formValueString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// jsLength returns the length of s in UTF-16 code units, like String.length in JavaScript.
+func jsLength(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
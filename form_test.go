@@ -0,0 +1,216 @@
+package datastar_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "maragu.dev/gomponents/html"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+func TestField_Attrs(t *testing.T) {
+	t.Run("should output bind, constraint attributes, signals, blur handler, effect, and custom validity regardless of touched state", func(t *testing.T) {
+		f := data.NewField("name", data.Required("Name is required."), data.MaxLength(10, ""))
+		assert.Equal(t, `<input data-bind="name" required maxlength="10" `+
+			`data-signals__ifmissing="{&#34;nameError&#34;:&#34;&#34;,&#34;nameTouched&#34;:false}" `+
+			`data-on:blur="$nameTouched = true" `+
+			`data-effect="$nameError = $nameTouched ? (String($name ?? &#39;&#39;).trim() === &#39;&#39; ? &#39;Name is required.&#39; : `+
+			`String($name ?? &#39;&#39;) !== &#39;&#39; &amp;&amp; String($name ?? &#39;&#39;).length &gt; 10 ? &#39;Must be at most 10 characters.&#39; : &#39;&#39;) : &#39;&#39;" `+
+			`data-custom-validity="(String($name ?? &#39;&#39;).trim() === &#39;&#39; ? &#39;Name is required.&#39; : `+
+			`String($name ?? &#39;&#39;) !== &#39;&#39; &amp;&amp; String($name ?? &#39;&#39;).length &gt; 10 ? &#39;Must be at most 10 characters.&#39; : &#39;&#39;) `+
+			`|| $nameError">`, Input(f.Attrs()))
+	})
+
+	t.Run("should output nested error and touched signals", func(t *testing.T) {
+		f := data.NewField("user.age", data.Range(0, 130, ""))
+		assert.Equal(t, `<input data-bind="user.age" min="0" max="130" `+
+			`data-signals__ifmissing="{&#34;user&#34;:{&#34;ageError&#34;:&#34;&#34;,&#34;ageTouched&#34;:false}}" `+
+			`data-on:blur="$user.ageTouched = true" `+
+			`data-effect="$user.ageError = $user.ageTouched ? (String($user.age ?? &#39;&#39;) !== &#39;&#39; &amp;&amp; `+
+			`(isNaN(Number(String($user.age ?? &#39;&#39;))) || Number(String($user.age ?? &#39;&#39;)) &lt; 0 || Number(String($user.age ?? &#39;&#39;)) &gt; 130) `+
+			`? &#39;Must be between 0 and 130.&#39; : &#39;&#39;) : &#39;&#39;" `+
+			`data-custom-validity="(String($user.age ?? &#39;&#39;) !== &#39;&#39; &amp;&amp; `+
+			`(isNaN(Number(String($user.age ?? &#39;&#39;))) || Number(String($user.age ?? &#39;&#39;)) &lt; 0 || Number(String($user.age ?? &#39;&#39;)) &gt; 130) `+
+			`? &#39;Must be between 0 and 130.&#39; : &#39;&#39;) || $user.ageError">`, Input(f.Attrs()))
+	})
+
+	t.Run("should output pattern attribute and escaped regular expression", func(t *testing.T) {
+		f := data.NewField("code", data.Pattern(`[a-z]{3}/\d+`, "Invalid code."))
+		assert.EqualString(t, `String($code ?? '') !== '' && !new RegExp('^(?:[a-z]{3}\\/[0-9]+)$', 'u').test(String($code ?? '')) ? 'Invalid code.' : ''`, f.Expr().String())
+		assert.EqualString(t, `[a-z]{3}\/[0-9]+`, patternAttr(t, f))
+	})
+
+	t.Run("should output empty string expression without rules", func(t *testing.T) {
+		assert.EqualString(t, "''", data.NewField("foo").Expr().String())
+	})
+}
+
+func TestPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{pattern: `abc`, expected: `abc`},
+		{pattern: `a.b`, expected: `a[^\n]b`},
+		{pattern: `(?s)a.b`, expected: `a[^]b`},
+		{pattern: `[^a]`, expected: `[^a]`},
+		{pattern: `[a-z_]+`, expected: `[_a-z]+`},
+		{pattern: `\d{2,}`, expected: `[0-9]{2,}`},
+		{pattern: `\w{1,3}?`, expected: `[0-9A-Z_a-z]{1,3}?`},
+		{pattern: `(a|b)c`, expected: `(?:[ab])c`},
+		{pattern: `(foo|bar)+`, expected: `(?:foo|bar)+`},
+		{pattern: `(?P<year>\d{4})-x`, expected: `(?:[0-9]{4})-x`},
+		{pattern: `(?i)ab`, expected: `[Aa][Bb]`},
+		{pattern: `(?i)k`, expected: `[Kk\u{212a}]`},
+		{pattern: `é\.\$ `, expected: `\u{e9}\.\$ `},
+		{pattern: `[.\-]`, expected: `[\u{2d}\u{2e}]`},
+		{pattern: `\bfoo\B`, expected: `\bfoo\B`},
+	}
+
+	for _, test := range tests {
+		t.Run("should convert "+test.pattern+" to JavaScript syntax", func(t *testing.T) {
+			f := data.NewField("foo", data.Pattern(test.pattern, ""))
+			assert.EqualString(t, test.expected, patternAttr(t, f))
+		})
+	}
+
+	t.Run("should panic on multi-line mode", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		data.Pattern(`(?m)^a$`, "")
+	})
+
+	t.Run("should panic on invalid patterns", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		data.Pattern(`(a`, "")
+	})
+}
+
+// patternAttr returns the value of the pattern attribute rendered by the field.
+func patternAttr(t *testing.T, f data.Field) string {
+	t.Helper()
+	var b strings.Builder
+	if err := Input(f.Attrs()).Render(&b); err != nil {
+		t.Fatal(err)
+	}
+	_, v, ok := strings.Cut(b.String(), ` pattern="`)
+	if !ok {
+		t.Fatalf("no pattern attribute in %v", b.String())
+	}
+	v, _, _ = strings.Cut(v, `"`)
+	return v
+}
+
+func TestField_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     data.Rule
+		value    any
+		expected string
+	}{
+		{name: "required with value", rule: data.Required(""), value: "a", expected: ""},
+		{name: "required with empty string", rule: data.Required(""), value: "", expected: "This field is required."},
+		{name: "required with whitespace", rule: data.Required(""), value: " \t", expected: "This field is required."},
+		{name: "required with nil", rule: data.Required(""), value: nil, expected: "This field is required."},
+		{name: "required with zero number", rule: data.Required(""), value: 0, expected: ""},
+		{name: "min length", rule: data.MinLength(3, ""), value: "ab", expected: "Must be at least 3 characters."},
+		{name: "min length with empty value", rule: data.MinLength(3, ""), value: "", expected: ""},
+		{name: "min length counts UTF-16 code units", rule: data.MinLength(2, ""), value: "😀", expected: ""},
+		{name: "max length", rule: data.MaxLength(3, "Too long."), value: "abcd", expected: "Too long."},
+		{name: "max length at limit", rule: data.MaxLength(3, ""), value: "abc", expected: ""},
+		{name: "pattern match", rule: data.Pattern(`\d+`, ""), value: "123", expected: ""},
+		{name: "pattern must match in full", rule: data.Pattern(`\d+`, ""), value: "123a", expected: "Must match the requested format."},
+		{name: "range with number", rule: data.Range(1, 10, ""), value: 11, expected: "Must be between 1 and 10."},
+		{name: "range with float", rule: data.Range(1, 10, ""), value: 9.5, expected: ""},
+		{name: "range with numeric string", rule: data.Range(1, 10, ""), value: "5", expected: ""},
+		{name: "range with non-numeric string", rule: data.Range(1, 10, ""), value: "five", expected: "Must be between 1 and 10."},
+		{name: "range with empty value", rule: data.Range(1, 10, ""), value: "", expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run("should check "+test.name, func(t *testing.T) {
+			f := data.NewField("foo", test.rule)
+			assert.EqualString(t, test.expected, f.Check(test.value))
+		})
+	}
+
+	t.Run("should return the message of the first failing rule", func(t *testing.T) {
+		f := data.NewField("foo", data.Required("first"), data.MinLength(3, "second"))
+		assert.EqualString(t, "first", f.Check(""))
+		assert.EqualString(t, "second", f.Check("a"))
+	})
+}
+
+func TestValidateFields(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	type signupForm struct {
+		Email string `json:"email"`
+		User  *user  `json:"user"`
+	}
+
+	fields := []data.Field{
+		data.NewField("email", data.Required(""), data.Pattern(`[^@]+@[^@]+`, "Invalid email.")),
+		data.NewField("user.name", data.Required("")),
+		data.NewField("user.age", data.Range(18, 130, "Must be an adult.")),
+	}
+
+	t.Run("should return nil for valid structs", func(t *testing.T) {
+		err := data.ValidateFields(signupForm{Email: "me@example.com", User: &user{Name: "Jane", Age: 42}}, fields...)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return validation errors for invalid structs", func(t *testing.T) {
+		err := data.ValidateFields(signupForm{Email: "me", User: &user{Age: 12}}, fields...)
+
+		var errs data.ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("expected ValidationErrors, got %v", err)
+		}
+		assert.EqualString(t, "invalid fields: email: Invalid email., user.age: Must be an adult., user.name: This field is required.", err.Error())
+		assert.EqualString(t, "map[emailError:Invalid email. emailTouched:true user:map[ageError:Must be an adult. ageTouched:true nameError:This field is required. nameTouched:true]]",
+			fmt.Sprint(errs.Signals(fields...)))
+	})
+
+	t.Run("should treat missing nested values as empty", func(t *testing.T) {
+		err := data.ValidateFields(signupForm{Email: "me@example.com"}, fields...)
+		assert.EqualString(t, "invalid fields: user.name: This field is required.", err.Error())
+	})
+
+	t.Run("should validate maps", func(t *testing.T) {
+		err := data.ValidateFields(map[string]any{"email": "me@example.com", "user": map[string]any{"name": "Jane", "age": 17.0}}, fields...)
+		assert.EqualString(t, "invalid fields: user.age: Must be an adult.", err.Error())
+	})
+}
+
+func TestValidationErrors_Signals(t *testing.T) {
+	t.Run("should set empty error signals for valid fields and mark all fields as touched", func(t *testing.T) {
+		fields := []data.Field{data.NewField("email"), data.NewField("name")}
+		errs := data.ValidationErrors{"name": "Required."}
+		assert.EqualString(t, "map[emailError: emailTouched:true nameError:Required. nameTouched:true]", fmt.Sprint(errs.Signals(fields...)))
+	})
+}
+
+func ExampleNewField() {
+	email := data.NewField("email", data.Required("Please enter your email."), data.MaxLength(100, ""))
+
+	fmt.Println(Input(Type("email"), email.Attrs()))
+	fmt.Println(Span(data.Text(data.Sig(email.ErrorSignal()))))
+	fmt.Println(data.ValidateFields(map[string]any{"email": ""}, email))
+	// Output: <input type="email" data-bind="email" required maxlength="100" data-signals__ifmissing="{&#34;emailError&#34;:&#34;&#34;,&#34;emailTouched&#34;:false}" data-on:blur="$emailTouched = true" data-effect="$emailError = $emailTouched ? (String($email ?? &#39;&#39;).trim() === &#39;&#39; ? &#39;Please enter your email.&#39; : String($email ?? &#39;&#39;) !== &#39;&#39; &amp;&amp; String($email ?? &#39;&#39;).length &gt; 100 ? &#39;Must be at most 100 characters.&#39; : &#39;&#39;) : &#39;&#39;" data-custom-validity="(String($email ?? &#39;&#39;).trim() === &#39;&#39; ? &#39;Please enter your email.&#39; : String($email ?? &#39;&#39;) !== &#39;&#39; &amp;&amp; String($email ?? &#39;&#39;).length &gt; 100 ? &#39;Must be at most 100 characters.&#39; : &#39;&#39;) || $emailError">
+	// <span data-text="$emailError"></span>
+	// invalid fields: email: Please enter your email.
+}
@@ -0,0 +1,119 @@
+package datastar
+
+import (
+	"net/http"
+
+	g "maragu.dev/gomponents"
+)
+
+// IsDatastarRequest reports whether the request was sent by a Datastar backend action,
+// based on the `Datastar-Request` header that the Datastar client sets.
+func IsDatastarRequest(r *http.Request) bool {
+	return r.Header.Get("Datastar-Request") == "true"
+}
+
+// Handler is like [http.Handler] but returns a [g.Node] and an error, like the handler in the gomponents http package.
+// See [Adapt] for how it's used.
+type Handler = func(http.ResponseWriter, *http.Request) (g.Node, error)
+
+type errorWithStatusCode interface {
+	StatusCode() int
+}
+
+// Adapt a [Handler] to a [http.HandlerFunc] that serves both full page loads and Datastar backend actions from the same URL.
+//
+// For Datastar requests (see [IsDatastarRequest]), the returned [g.Node] is sent in a `datastar-patch-elements` event,
+// configured with opts. For all other requests, the node is passed to page, which should wrap it in a full HTML page,
+// and the result is rendered to the response.
+// If the node is nil for a Datastar request, the status code [http.StatusNoContent] (204) is sent.
+// The `Vary: Datastar-Request` header is set, so caches keep the two responses apart.
+//
+//	http.Handle("/todos", data.Adapt(layout, func(w http.ResponseWriter, r *http.Request) (g.Node, error) {
+//		return TodoList(todos), nil
+//	}))
+//
+// If the [Handler] returns an error, and it implements a "StatusCode() int" method, that HTTP status code is sent
+// in the response header. Otherwise, the status code [http.StatusInternalServerError] (500) is used.
+// In that case, the node is rendered as-is for Datastar requests, since the client doesn't patch elements from error responses.
+//
+// The handler can also write the response itself, for example by streaming events with [NewSSE], and return a nil node.
+// Status codes are only sent if nothing has been written to the response yet, so errors after the response has started,
+// including errors while rendering, just end the response.
+func Adapt(page func(g.Node) g.Node, h Handler, opts ...PatchElementsOption) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Datastar-Request")
+
+		isDatastar := IsDatastarRequest(r)
+
+		rw := &responseWriter{ResponseWriter: w}
+		n, err := h(rw, r)
+		if err != nil && !rw.written {
+			switch v := err.(type) {
+			case errorWithStatusCode:
+				rw.WriteHeader(v.StatusCode())
+			default:
+				rw.WriteHeader(http.StatusInternalServerError)
+			}
+		}
+
+		if isDatastar && err == nil {
+			if n == nil {
+				if !rw.written {
+					rw.WriteHeader(http.StatusNoContent)
+				}
+				return
+			}
+			// Render before opening the stream, so render errors can still get an error status code
+			e, err := patchElementsEvent(n, opts)
+			if err != nil {
+				if !rw.written {
+					http.Error(rw, "error rendering node: "+err.Error(), http.StatusInternalServerError)
+				}
+				return
+			}
+			// Ignore the error, since it only happens when the client has disconnected
+			_ = NewSSE(rw, r).send(e)
+			return
+		}
+
+		if n == nil {
+			return
+		}
+		if !isDatastar {
+			n = page(n)
+		}
+
+		if err := n.Render(rw); err != nil && !rw.written {
+			http.Error(rw, "error rendering node: "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+// responseWriter records whether anything has been written to the response, so [Adapt] only sends a status code before that.
+type responseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush satisfies [http.Flusher], so [SSE] streams through the wrapper.
+func (w *responseWriter) Flush() {
+	w.written = true
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped [http.ResponseWriter], for [http.ResponseController].
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,194 @@
+package datastar_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	g "maragu.dev/gomponents"
+	. "maragu.dev/gomponents/html"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/datastartest"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+func TestIsDatastarRequest(t *testing.T) {
+	t.Run("should be true for requests from Datastar", func(t *testing.T) {
+		r := datastartest.NewRequest(http.MethodGet, "/", nil)
+		assert.EqualString(t, "true", fmt.Sprint(data.IsDatastarRequest(r)))
+	})
+
+	t.Run("should be false for other requests", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.EqualString(t, "false", fmt.Sprint(data.IsDatastarRequest(r)))
+	})
+}
+
+type statusCodeError struct{}
+
+func (statusCodeError) Error() string   { return "not found" }
+func (statusCodeError) StatusCode() int { return http.StatusNotFound }
+
+// headerRecorder counts calls to WriteHeader, to catch superfluous ones.
+type headerRecorder struct {
+	*httptest.ResponseRecorder
+	writeHeaderCalls int
+}
+
+func (r *headerRecorder) WriteHeader(statusCode int) {
+	r.writeHeaderCalls++
+	r.ResponseRecorder.WriteHeader(statusCode)
+}
+
+func TestAdapt(t *testing.T) {
+	page := func(n g.Node) g.Node {
+		return Body(n)
+	}
+
+	t.Run("should render the full page for normal requests", func(t *testing.T) {
+		h := data.Adapt(page, func(w http.ResponseWriter, r *http.Request) (g.Node, error) {
+			return Div(ID("foo"), g.Text("Hello")), nil
+		})
+
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.EqualString(t, "200", fmt.Sprint(w.Code))
+		assert.EqualString(t, "Datastar-Request", w.Header().Get("Vary"))
+		assert.EqualString(t, `<body><div id="foo">Hello</div></body>`, w.Body.String())
+	})
+
+	t.Run("should send a patch elements event for Datastar requests", func(t *testing.T) {
+		h := data.Adapt(page, func(w http.ResponseWriter, r *http.Request) (g.Node, error) {
+			return Div(ID("foo"), g.Text("Hello")), nil
+		}, data.WithViewTransition())
+
+		rec := datastartest.NewRecorder()
+		h(rec, datastartest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.EqualString(t, "200", fmt.Sprint(rec.Code))
+		assert.EqualString(t, "text/event-stream", rec.Header().Get("Content-Type"))
+		assert.EqualString(t, "Datastar-Request", rec.Header().Get("Vary"))
+		rec.AssertPatchedElement(t, "#foo", "Hello")
+		assert.EqualString(t, "true", fmt.Sprint(rec.PatchElementsEvents(t)[0].UseViewTransition))
+	})
+
+	t.Run("should send no content for Datastar requests without a node", func(t *testing.T) {
+		h := data.Adapt(page, func(w http.ResponseWriter, r *http.Request) (g.Node, error) {
+			return nil, nil
+		})
+
+		w := httptest.NewRecorder()
+		h(w, datastartest.NewRequest(http.MethodPost, "/", nil))
+
+		assert.EqualString(t, "204", fmt.Sprint(w.Code))
+		assert.EqualString(t, "", w.Body.String())
+	})
+
+	t.Run("should send the status code from errors", func(t *testing.T) {
+		h := data.Adapt(page, func(w http.ResponseWriter, r *http.Request) (g.Node, error) {
+			return Div(g.Text("Not found")), statusCodeError{}
+		})
+
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.EqualString(t, "404", fmt.Sprint(w.Code))
+		assert.EqualString(t, `<body><div>Not found</div></body>`, w.Body.String())
+
+		w = httptest.NewRecorder()
+		h(w, datastartest.NewRequest(http.MethodGet, "/", nil))
+		assert.EqualString(t, "404", fmt.Sprint(w.Code))
+		assert.EqualString(t, `<div>Not found</div>`, w.Body.String())
+	})
+
+	t.Run("should send status code 500 for other errors", func(t *testing.T) {
+		h := data.Adapt(page, func(w http.ResponseWriter, r *http.Request) (g.Node, error) {
+			return nil, errors.New("oh no")
+		})
+
+		w := httptest.NewRecorder()
+		h(w, datastartest.NewRequest(http.MethodGet, "/", nil))
+		assert.EqualString(t, "500", fmt.Sprint(w.Code))
+	})
+
+	t.Run("should send status code 500 if rendering fails for Datastar requests", func(t *testing.T) {
+		h := data.Adapt(page, func(w http.ResponseWriter, r *http.Request) (g.Node, error) {
+			return g.NodeFunc(func(io.Writer) error { return errors.New("oh no") }), nil
+		})
+
+		w := httptest.NewRecorder()
+		h(w, datastartest.NewRequest(http.MethodGet, "/", nil))
+		assert.EqualString(t, "500", fmt.Sprint(w.Code))
+		assert.EqualString(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("should not write a status code if the handler streams the response itself", func(t *testing.T) {
+		h := data.Adapt(page, func(w http.ResponseWriter, r *http.Request) (g.Node, error) {
+			sse := data.NewSSE(w, r)
+			return nil, sse.PatchElements(Div(ID("foo"), g.Text("Hello")))
+		})
+
+		w := &headerRecorder{ResponseRecorder: httptest.NewRecorder()}
+		h(w, datastartest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.EqualString(t, "200", fmt.Sprint(w.Code))
+		assert.EqualString(t, "1", fmt.Sprint(w.writeHeaderCalls))
+		assert.EqualString(t, "true", fmt.Sprint(w.Flushed))
+		assert.EqualString(t, "event: datastar-patch-elements\ndata: elements <div id=\"foo\">Hello</div>\n\n", w.Body.String())
+	})
+
+	t.Run("should not write a status code for errors after the handler has written the response", func(t *testing.T) {
+		h := data.Adapt(page, func(w http.ResponseWriter, r *http.Request) (g.Node, error) {
+			_, _ = w.Write([]byte("partial"))
+			return nil, errors.New("oh no")
+		})
+
+		w := &headerRecorder{ResponseRecorder: httptest.NewRecorder()}
+		h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.EqualString(t, "200", fmt.Sprint(w.Code))
+		assert.EqualString(t, "0", fmt.Sprint(w.writeHeaderCalls))
+		assert.EqualString(t, "partial", w.Body.String())
+	})
+
+	t.Run("should not write an error if rendering fails after the response has started", func(t *testing.T) {
+		h := data.Adapt(page, func(w http.ResponseWriter, r *http.Request) (g.Node, error) {
+			return g.NodeFunc(func(w io.Writer) error {
+				_, _ = io.WriteString(w, "<div>")
+				return errors.New("oh no")
+			}), nil
+		})
+
+		w := &headerRecorder{ResponseRecorder: httptest.NewRecorder()}
+		h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.EqualString(t, "200", fmt.Sprint(w.Code))
+		assert.EqualString(t, "0", fmt.Sprint(w.writeHeaderCalls))
+		assert.EqualString(t, "<body><div>", w.Body.String())
+	})
+}
+
+func ExampleAdapt() {
+	page := func(n g.Node) g.Node {
+		return HTML(Body(n))
+	}
+
+	h := data.Adapt(page, func(w http.ResponseWriter, r *http.Request) (g.Node, error) {
+		return Div(ID("greeting"), g.Text("Hello")), nil
+	})
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	fmt.Println(w.Body.String())
+
+	w = httptest.NewRecorder()
+	h(w, datastartest.NewRequest(http.MethodGet, "/", nil))
+	fmt.Print(w.Body.String())
+	// Output: <html><body><div id="greeting">Hello</div></body></html>
+	// event: datastar-patch-elements
+	// data: elements <div id="greeting">Hello</div>
+}
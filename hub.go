@@ -0,0 +1,173 @@
+package datastar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	g "maragu.dev/gomponents"
+)
+
+// ErrSlowSubscriber is returned by [Hub.Subscribe] if the client was dropped
+// because it didn't keep up with the events published to it.
+var ErrSlowSubscriber = errors.New("subscriber too slow, dropped")
+
+// Hub broadcasts events to many [SSE] streams, grouped by topic.
+// Events are rendered once, and the same pre-rendered frame is written to all subscribers of the topic.
+// It is safe for concurrent use.
+type Hub struct {
+	bufferSize  int
+	mu          sync.RWMutex
+	subscribers map[string]map[*subscriber]struct{}
+}
+
+// HubOption configures a [Hub].
+type HubOption interface {
+	applyHub(*Hub)
+}
+
+type hubOption func(*Hub)
+
+func (o hubOption) applyHub(h *Hub) {
+	o(h)
+}
+
+// WithBufferSize sets how many events are buffered per subscriber, before it is dropped as too slow. Defaults to 16.
+// Panics if the size is negative.
+func WithBufferSize(size int) HubOption {
+	if size < 0 {
+		panic(fmt.Sprintf("buffer size must not be negative, but is: %v", size))
+	}
+	return hubOption(func(h *Hub) {
+		h.bufferSize = size
+	})
+}
+
+// NewHub with the given options.
+func NewHub(opts ...HubOption) *Hub {
+	h := &Hub{
+		bufferSize:  16,
+		subscribers: map[string]map[*subscriber]struct{}{},
+	}
+	for _, opt := range opts {
+		opt.applyHub(h)
+	}
+	return h
+}
+
+type subscriber struct {
+	frames   chan []byte
+	dropped  chan struct{}
+	dropOnce sync.Once
+}
+
+func (s *subscriber) drop() {
+	s.dropOnce.Do(func() {
+		close(s.dropped)
+	})
+}
+
+// Subscribe the [SSE] stream to events published to any of the topics, and write them to it until the
+// request context is done, which happens when the client disconnects.
+// Subscribe blocks, so send any initial events on the stream before calling it.
+//
+// Returns nil when the request context is done, [ErrSlowSubscriber] if the client was dropped for not keeping up,
+// or the error from writing to the client.
+func (h *Hub) Subscribe(sse *SSE, topics ...string) error {
+	sub := &subscriber{
+		frames:  make(chan []byte, h.bufferSize),
+		dropped: make(chan struct{}),
+	}
+
+	h.add(sub, topics)
+	defer h.remove(sub, topics)
+
+	ctx := sse.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sub.dropped:
+			return ErrSlowSubscriber
+		case frame := <-sub.frames:
+			if err := sse.write(frame); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+}
+
+// Subscribers returns the number of subscribers to the topic.
+func (h *Hub) Subscribers(topic string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers[topic])
+}
+
+// PatchElements renders the node once and sends it to all subscribers of the topic, like [SSE.PatchElements].
+// Only returns an error if rendering fails, since slow subscribers are dropped instead of blocking.
+func (h *Hub) PatchElements(topic string, node g.Node, opts ...PatchElementsOption) error {
+	e, err := patchElementsEvent(node, opts)
+	if err != nil {
+		return err
+	}
+	return h.publish(topic, e)
+}
+
+// PatchSignals sends the signals to all subscribers of the topic, like [SSE.PatchSignals].
+// Only returns an error if marshalling fails, since slow subscribers are dropped instead of blocking.
+func (h *Hub) PatchSignals(topic string, signals any, opts ...PatchSignalsOption) error {
+	e, err := patchSignalsEvent(signals, opts)
+	if err != nil {
+		return err
+	}
+	return h.publish(topic, e)
+}
+
+func (h *Hub) publish(topic string, e event) error {
+	var b bytes.Buffer
+	if err := e.writeTo(&b); err != nil {
+		return err
+	}
+	frame := b.Bytes()
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subscribers[topic] {
+		select {
+		case sub.frames <- frame:
+		default:
+			sub.drop()
+		}
+	}
+	return nil
+}
+
+func (h *Hub) add(sub *subscriber, topics []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, topic := range topics {
+		if h.subscribers[topic] == nil {
+			h.subscribers[topic] = map[*subscriber]struct{}{}
+		}
+		h.subscribers[topic][sub] = struct{}{}
+	}
+}
+
+func (h *Hub) remove(sub *subscriber, topics []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, topic := range topics {
+		delete(h.subscribers[topic], sub)
+		if len(h.subscribers[topic]) == 0 {
+			delete(h.subscribers, topic)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package datastar_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	g "maragu.dev/gomponents"
+	. "maragu.dev/gomponents/html"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+func TestHub(t *testing.T) {
+	t.Run("should send published events to subscribers of the topic", func(t *testing.T) {
+		hub := data.NewHub()
+
+		a := subscribe(t, hub, "dashboard")
+		b := subscribe(t, hub, "dashboard", "alerts")
+		c := subscribe(t, hub, "alerts")
+		waitForSubscribers(t, hub, "dashboard", 2)
+		waitForSubscribers(t, hub, "alerts", 2)
+
+		assert.NoError(t, hub.PatchElements("dashboard", Div(ID("count"), g.Text("1"))))
+		assert.NoError(t, hub.PatchSignals("alerts", map[string]any{"alert": true}))
+
+		a.wait(t)
+		b.wait(t)
+		b.wait(t)
+		c.wait(t)
+
+		a.stop(t)
+		b.stop(t)
+		c.stop(t)
+
+		patchElements := "event: datastar-patch-elements\ndata: elements <div id=\"count\">1</div>\n\n"
+		patchSignals := "event: datastar-patch-signals\ndata: signals {\"alert\":true}\n\n"
+		assert.EqualString(t, patchElements, a.w.Body.String())
+		assert.EqualString(t, patchElements+patchSignals, b.w.Body.String())
+		assert.EqualString(t, patchSignals, c.w.Body.String())
+	})
+
+	t.Run("should remove subscribers when the request context is done", func(t *testing.T) {
+		hub := data.NewHub()
+
+		a := subscribe(t, hub, "dashboard")
+		waitForSubscribers(t, hub, "dashboard", 1)
+
+		err := a.stop(t)
+		assert.NoError(t, err)
+		assert.EqualString(t, "0", fmt.Sprint(hub.Subscribers("dashboard")))
+	})
+
+	t.Run("should drop slow subscribers", func(t *testing.T) {
+		hub := data.NewHub(data.WithBufferSize(1))
+
+		a := subscribe(t, hub, "dashboard")
+		waitForSubscribers(t, hub, "dashboard", 1)
+		a.w.block = make(chan struct{})
+
+		// The first event blocks in the write, the second fills the buffer, and the third drops the subscriber
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, hub.PatchSignals("dashboard", map[string]any{"i": i}))
+			if i == 0 {
+				a.waitForWrite(t)
+			}
+		}
+		close(a.w.block)
+
+		select {
+		case err := <-a.done:
+			if !errors.Is(err, data.ErrSlowSubscriber) {
+				t.Fatalf("expected ErrSlowSubscriber, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for subscriber to be dropped")
+		}
+		a.cancel()
+		assert.EqualString(t, "0", fmt.Sprint(hub.Subscribers("dashboard")))
+	})
+
+	t.Run("should return rendering errors", func(t *testing.T) {
+		hub := data.NewHub()
+		err := hub.PatchSignals("dashboard", make(chan int))
+		assert.Error(t, err)
+	})
+
+	t.Run("should panic on negative buffer size", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		data.WithBufferSize(-1)
+	})
+}
+
+type testSubscriber struct {
+	w      *flushRecorder
+	cancel context.CancelFunc
+	done   chan error
+}
+
+// subscribe to the hub in a goroutine, with a recorder that signals every flush.
+func subscribe(t *testing.T, hub *data.Hub, topics ...string) *testSubscriber {
+	t.Helper()
+
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder(), flushes: make(chan struct{}, 16), writes: make(chan struct{}, 16)}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	sse := data.NewSSE(w, r)
+	<-w.flushes
+
+	s := &testSubscriber{w: w, cancel: cancel, done: make(chan error, 1)}
+	go func() {
+		s.done <- hub.Subscribe(sse, topics...)
+	}()
+	return s
+}
+
+// wait for an event to be flushed to the subscriber.
+func (s *testSubscriber) wait(t *testing.T) {
+	t.Helper()
+
+	select {
+	case <-s.w.flushes:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+}
+
+// waitForWrite to start on the subscriber.
+func (s *testSubscriber) waitForWrite(t *testing.T) {
+	t.Helper()
+
+	select {
+	case <-s.w.writes:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for write")
+	}
+}
+
+// stop the subscriber by canceling the request context, and return the error from Subscribe.
+func (s *testSubscriber) stop(t *testing.T) error {
+	t.Helper()
+
+	s.cancel()
+	select {
+	case err := <-s.done:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for subscriber to stop")
+		return nil
+	}
+}
+
+func waitForSubscribers(t *testing.T, hub *data.Hub, topic string, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for hub.Subscribers(topic) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %v subscribers to %v", n, topic)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// flushRecorder is an [httptest.ResponseRecorder] that signals writes and flushes,
+// and can block writes to simulate a slow client.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes chan struct{}
+	writes  chan struct{}
+	block   chan struct{}
+}
+
+func (r *flushRecorder) Write(b []byte) (int, error) {
+	if r.block != nil {
+		r.writes <- struct{}{}
+		<-r.block
+	}
+	return r.ResponseRecorder.Write(b)
+}
+
+func (r *flushRecorder) Flush() {
+	r.ResponseRecorder.Flush()
+	r.flushes <- struct{}{}
+}
@@ -30,3 +30,21 @@ func Error(t *testing.T, err error) {
 		t.Fatal("error is nil")
 	}
 }
+
+// NoError checks for a nil error.
+func NoError(t *testing.T, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatal("error is not nil:", err)
+	}
+}
+
+// EqualString checks for equality between the given expected and actual strings.
+func EqualString(t *testing.T, expected, actual string) {
+	t.Helper()
+
+	if expected != actual {
+		t.Fatalf(`expected "%v" but got "%v"`, expected, actual)
+	}
+}
@@ -0,0 +1,230 @@
+package datastar
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JS encodes a Go value as a JavaScript literal, safe to embed in any expression.
+//
+//   - Strings become single-quoted string literals, with quotes, backslashes, line terminators and "<" escaped,
+//     so user data can never break out of the literal or a surrounding script element.
+//   - Booleans and numbers become their literals, including NaN and Infinity.
+//   - Slices and arrays become array literals, and maps become object literals with sorted keys.
+//   - Byte slices become base64-encoded strings, like with [json.Marshal].
+//   - [time.Time] becomes an RFC 3339 string, and [time.Duration] becomes a number of nanoseconds, like with [json.Marshal],
+//     so values agree with the signals sent by [Signals] and [SSE.PatchSignals].
+//   - Structs and other values implementing [json.Marshaler], also through a pointer receiver, are encoded as JSON.
+//   - nil becomes null.
+//
+// The result can be used as a value with [Attr], [Class], [Style], [Computed], and other attributes taking expressions:
+//
+//	data.Attr("title", data.JS(user.Name))
+//
+// Panics if the value cannot be encoded, such as for channels and functions.
+func JS(v any) string {
+	js, err := toJS(v)
+	if err != nil {
+		panic(err.Error())
+	}
+	return js
+}
+
+func toJS(v any) (string, error) {
+	var b strings.Builder
+	if err := writeJS(&b, reflect.ValueOf(v)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+func writeJS(b *strings.Builder, v reflect.Value) error {
+	if !v.IsValid() {
+		b.WriteString("null")
+		return nil
+	}
+
+	switch v.Type() {
+	case timeType:
+		b.WriteString(quote(v.Interface().(time.Time).Format(time.RFC3339Nano)))
+		return nil
+	}
+
+	if v.Kind() != reflect.Interface && v.Type().Implements(jsonMarshalerType) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		return writeJSON(b, v)
+	}
+	if v.CanAddr() && reflect.PointerTo(v.Type()).Implements(jsonMarshalerType) {
+		return writeJSON(b, v.Addr())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		return writeJS(b, v.Elem())
+
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()))
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10))
+
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			b.WriteString("NaN")
+		case math.IsInf(f, 1):
+			b.WriteString("Infinity")
+		case math.IsInf(f, -1):
+			b.WriteString("-Infinity")
+		default:
+			b.WriteString(strconv.FormatFloat(f, 'g', -1, v.Type().Bits()))
+		}
+
+	case reflect.String:
+		b.WriteString(quote(v.String()))
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			b.WriteString(quote(base64.StdEncoding.EncodeToString(v.Bytes())))
+			return nil
+		}
+		b.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if err := writeJS(b, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		b.WriteString("]")
+
+	case reflect.Map:
+		if v.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		keys := make([]string, 0, v.Len())
+		values := map[string]reflect.Value{}
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			keys = append(keys, key)
+			values[key] = iter.Value()
+		}
+		sort.Strings(keys)
+
+		b.WriteString("{")
+		for i, key := range keys {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if isIdentifier(key) {
+				b.WriteString(key)
+			} else {
+				b.WriteString(quote(key))
+			}
+			b.WriteString(": ")
+			if err := writeJS(b, values[key]); err != nil {
+				return err
+			}
+		}
+		b.WriteString("}")
+
+	case reflect.Struct:
+		return writeJSON(b, v)
+
+	default:
+		return fmt.Errorf("cannot encode %v as JavaScript", v.Type())
+	}
+
+	return nil
+}
+
+// writeJSON writes the value as JSON, which is valid JavaScript.
+// json.Marshal escapes "<", ">", "&", and line terminators in strings, so the result is safe to embed.
+func writeJSON(b *strings.Builder, v reflect.Value) error {
+	j, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Errorf("cannot encode %v as JavaScript: %w", v.Type(), err)
+	}
+	b.Write(j)
+	return nil
+}
+
+// isIdentifier reports whether s is a valid JavaScript identifier, and can be used as an object key without quotes.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// quote a string as a single-quoted JavaScript string literal.
+// Besides quotes and backslashes, line terminators and "<" are escaped,
+// so the literal is safe to use anywhere, including inside script elements.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '<':
+			b.WriteString(`\x3C`)
+		case '\u2028':
+			b.WriteString(`\u2028`)
+		case '\u2029':
+			b.WriteString(`\u2029`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				b.WriteString(fmt.Sprintf(`\x%02X`, r))
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package datastar_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	. "maragu.dev/gomponents/html"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+type pointerMarshaler struct{}
+
+func (m *pointerMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`"pointer"`), nil
+}
+
+type valueMarshaler struct{}
+
+func (m valueMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`"value"`), nil
+}
+
+func TestJS(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	type label string
+
+	name := "Jane"
+
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{name: "nil", value: nil, expected: `null`},
+		{name: "string", value: "it's", expected: `'it\'s'`},
+		{name: "string with backslash and double quotes", value: `a\b"c`, expected: `'a\\b"c'`},
+		{name: "string with script", value: "</script>", expected: `'\x3C/script>'`},
+		{name: "string with line terminators", value: "a\nb\r\u2028\u2029", expected: `'a\nb\r\u2028\u2029'`},
+		{name: "string with control characters", value: "a\x00b", expected: `'a\x00b'`},
+		{name: "named string", value: label("foo"), expected: `'foo'`},
+		{name: "true", value: true, expected: `true`},
+		{name: "int", value: -42, expected: `-42`},
+		{name: "uint", value: uint8(42), expected: `42`},
+		{name: "float", value: 1.5, expected: `1.5`},
+		{name: "float32", value: float32(0.1), expected: `0.1`},
+		{name: "NaN", value: math.NaN(), expected: `NaN`},
+		{name: "Infinity", value: math.Inf(1), expected: `Infinity`},
+		{name: "-Infinity", value: math.Inf(-1), expected: `-Infinity`},
+		{name: "pointer", value: &name, expected: `'Jane'`},
+		{name: "nil pointer", value: (*string)(nil), expected: `null`},
+		{name: "slice", value: []any{1, "a", nil, []int{2}}, expected: `[1, 'a', null, [2]]`},
+		{name: "nil slice", value: []string(nil), expected: `null`},
+		{name: "array", value: [2]bool{true, false}, expected: `[true, false]`},
+		{name: "map", value: map[string]any{"b": 1, "a": "x", "font-bold": true}, expected: `{a: 'x', b: 1, 'font-bold': true}`},
+		{name: "map with int keys", value: map[int]string{2: "b", 1: "a"}, expected: `{'1': 'a', '2': 'b'}`},
+		{name: "nil map", value: map[string]int(nil), expected: `null`},
+		{name: "time", value: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), expected: `'2025-01-02T03:04:05Z'`},
+		{name: "duration", value: 1500 * time.Millisecond, expected: `1500000000`},
+		{name: "struct with duration", value: struct{ D time.Duration }{D: 1500 * time.Millisecond}, expected: `{"D":1500000000}`},
+		{name: "struct", value: user{Name: "</script>", Age: 42}, expected: `{"name":"\u003c/script\u003e","age":42}`},
+		{name: "bytes", value: []byte("hi"), expected: `'aGk='`},
+		{name: "nil bytes", value: []byte(nil), expected: `null`},
+		{name: "value marshaler", value: valueMarshaler{}, expected: `"value"`},
+		{name: "pointer to value marshaler", value: &valueMarshaler{}, expected: `"value"`},
+		{name: "nil pointer to value marshaler", value: (*valueMarshaler)(nil), expected: `null`},
+		{name: "pointer marshaler", value: &pointerMarshaler{}, expected: `"pointer"`},
+		{name: "nil pointer marshaler", value: (*pointerMarshaler)(nil), expected: `null`},
+		{name: "slice of pointer marshalers", value: []pointerMarshaler{{}}, expected: `["pointer"]`},
+		{name: "map of pointer marshalers", value: map[string]*pointerMarshaler{"a": {}}, expected: `{a: "pointer"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualString(t, test.expected, data.JS(test.value))
+		})
+	}
+
+	t.Run("should panic on unsupported types", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic for unsupported type")
+			}
+		}()
+		data.JS(map[string]any{"foo": func() {}})
+	})
+
+	t.Run("should be safe to use with the pair helpers", func(t *testing.T) {
+		n := Div(data.Attr("title", data.JS(`"><script>alert('x')</script>`)))
+		assert.Equal(t, `<div data-attr="{title: &#39;&#34;&gt;\x3Cscript&gt;alert(\&#39;x\&#39;)\x3C/script&gt;&#39;}"></div>`, n)
+	})
+}
+
+func ExampleJS() {
+	fmt.Print(Div(data.Attr("title", data.JS("It's mine"))))
+	// Output: <div data-attr="{title: &#39;It\&#39;s mine&#39;}"></div>
+}
+
+func ExampleJS_map() {
+	fmt.Print(Div(data.Init("console.log(" + data.JS(map[string]any{"count": 1, "name": "Jane"}) + ")")))
+	// Output: <div data-init="console.log({count: 1, name: &#39;Jane&#39;})"></div>
+}
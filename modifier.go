@@ -0,0 +1,356 @@
+package datastar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Modifier is a modifier accepted by all attributes that take a name: [On], [Indicator], [Ref], [Signals], [Bind], [Class], and [Computed].
+// These are the case modifiers, such as [ModifierCase] and [ModifierCamel], so a variable of this type can hold any of them.
+//
+// Other modifier constants, such as [ModifierWindow] or [ModifierThreshold], are only accepted by the attributes they are valid for,
+// so passing a modifier to an attribute that doesn't support it is a compile error.
+// Each attribute has its own modifier interface, such as [OnModifier] or [OnIntersectModifier], listing what it accepts.
+type Modifier interface {
+	OnModifier
+	IndicatorModifier
+	RefModifier
+	SignalsModifier
+	BindModifier
+	ClassModifier
+	ComputedModifier
+}
+
+// AnimateModifier is a modifier accepted by [Animate].
+type AnimateModifier interface {
+	fmt.Stringer
+	isAnimateModifier()
+}
+
+// OnModifier is a modifier accepted by [On].
+type OnModifier interface {
+	fmt.Stringer
+	isOnModifier()
+}
+
+// OnIntersectModifier is a modifier accepted by [OnIntersect].
+type OnIntersectModifier interface {
+	fmt.Stringer
+	isOnIntersectModifier()
+}
+
+// OnIntervalModifier is a modifier accepted by [OnInterval].
+type OnIntervalModifier interface {
+	fmt.Stringer
+	isOnIntervalModifier()
+}
+
+// OnRAFModifier is a modifier accepted by [OnRAF].
+type OnRAFModifier interface {
+	fmt.Stringer
+	isOnRAFModifier()
+}
+
+// OnResizeModifier is a modifier accepted by [OnResize].
+type OnResizeModifier interface {
+	fmt.Stringer
+	isOnResizeModifier()
+}
+
+// ScrollIntoViewModifier is a modifier accepted by [ScrollIntoView].
+type ScrollIntoViewModifier interface {
+	fmt.Stringer
+	isScrollIntoViewModifier()
+}
+
+// PersistModifier is a modifier accepted by [Persist].
+type PersistModifier interface {
+	fmt.Stringer
+	isPersistModifier()
+}
+
+// QueryStringModifier is a modifier accepted by [QueryString].
+type QueryStringModifier interface {
+	fmt.Stringer
+	isQueryStringModifier()
+}
+
+// InitModifier is a modifier accepted by [Init].
+type InitModifier interface {
+	fmt.Stringer
+	isInitModifier()
+}
+
+// OnSignalPatchModifier is a modifier accepted by [OnSignalPatch].
+type OnSignalPatchModifier interface {
+	fmt.Stringer
+	isOnSignalPatchModifier()
+}
+
+// BindModifier is a modifier accepted by [BindKey].
+type BindModifier interface {
+	fmt.Stringer
+	isBindModifier()
+}
+
+// ClassModifier is a modifier accepted by [ClassKey].
+type ClassModifier interface {
+	fmt.Stringer
+	isClassModifier()
+}
+
+// ComputedModifier is a modifier accepted by [ComputedKey].
+type ComputedModifier interface {
+	fmt.Stringer
+	isComputedModifier()
+}
+
+// IndicatorModifier is a modifier accepted by [Indicator].
+type IndicatorModifier interface {
+	fmt.Stringer
+	isIndicatorModifier()
+}
+
+// RefModifier is a modifier accepted by [Ref].
+type RefModifier interface {
+	fmt.Stringer
+	isRefModifier()
+}
+
+// JSONSignalsModifier is a modifier accepted by [JSONSignals].
+type JSONSignalsModifier interface {
+	fmt.Stringer
+	isJSONSignalsModifier()
+}
+
+// SignalsModifier is a modifier accepted by [Signals], [SignalsFrom], and [SignalKey].
+type SignalsModifier interface {
+	fmt.Stringer
+	isSignalsModifier()
+}
+
+// IgnoreModifier is a modifier accepted by [Ignore].
+type IgnoreModifier interface {
+	fmt.Stringer
+	isIgnoreModifier()
+}
+
+// EventModifier is a modifier for event listeners.
+type EventModifier string
+
+// CaseModifier is the __case modifier and its tags, which convert the casing of keys.
+type CaseModifier string
+
+// OnceModifier is the __once modifier, which only triggers once.
+type OnceModifier string
+
+// RateLimitModifier is a modifier that debounces or throttles.
+type RateLimitModifier string
+
+// DelayModifier is the __delay modifier.
+type DelayModifier string
+
+// TimingModifier is a tag for timing modifiers, such as a [Duration] or leading/trailing edges.
+type TimingModifier string
+
+// ViewTransitionModifier is the __viewtransition modifier, which wraps the expression in a view transition.
+type ViewTransitionModifier string
+
+// VisibilityModifier is a modifier for when an element counts as intersecting, including a [Threshold].
+type VisibilityModifier string
+
+// IntervalModifier is the __duration modifier for intervals and animations.
+type IntervalModifier string
+
+// EasingModifier is the __easing modifier and its tags, which set the easing function of an animation.
+type EasingModifier string
+
+// IfMissingModifier is the __ifmissing modifier, which only patches signals that don't exist yet.
+type IfMissingModifier string
+
+// TerseModifier is the __terse modifier, which outputs JSON without indentation.
+type TerseModifier string
+
+// StorageModifier is the __session modifier, which persists signals in session storage instead of local storage.
+type StorageModifier string
+
+// URLModifier is a modifier for how signals are synced with the URL.
+type URLModifier string
+
+// ScrollModifier is a modifier for how an element is scrolled into view.
+type ScrollModifier string
+
+// SelfModifier is the __self modifier, which only applies to the element itself and not its descendants.
+type SelfModifier string
+
+const (
+	ModifierCapture EventModifier = "__capture"
+	ModifierOutside EventModifier = "__outside"
+	ModifierPassive EventModifier = "__passive"
+	ModifierPrevent EventModifier = "__prevent"
+	ModifierStop    EventModifier = "__stop"
+	ModifierWindow  EventModifier = "__window"
+
+	ModifierCase CaseModifier = "__case"
+
+	ModifierOnce OnceModifier = "__once"
+
+	ModifierDebounce RateLimitModifier = "__debounce"
+	ModifierThrottle RateLimitModifier = "__throttle"
+
+	ModifierDelay DelayModifier = "__delay"
+
+	ModifierViewTransition ViewTransitionModifier = "__viewtransition"
+
+	ModifierExit      VisibilityModifier = "__exit"
+	ModifierFull      VisibilityModifier = "__full"
+	ModifierHalf      VisibilityModifier = "__half"
+	ModifierThreshold VisibilityModifier = "__threshold"
+
+	ModifierDuration IntervalModifier = "__duration"
+
+	ModifierEasing EasingModifier = "__easing"
+
+	ModifierIfMissing IfMissingModifier = "__ifmissing"
+
+	ModifierTerse TerseModifier = "__terse"
+
+	ModifierSelf SelfModifier = "__self"
+
+	ModifierSession StorageModifier = "__session"
+
+	ModifierFilter  URLModifier = "__filter"  // Leave out signals with empty values.
+	ModifierHistory URLModifier = "__history" // Add a history entry for each change.
+
+	ModifierSmooth   ScrollModifier = "__smooth"   // Animate scrolling smoothly.
+	ModifierInstant  ScrollModifier = "__instant"  // Scroll instantly.
+	ModifierAuto     ScrollModifier = "__auto"     // Let the browser decide how to scroll.
+	ModifierHStart   ScrollModifier = "__hstart"   // Align to the left.
+	ModifierHCenter  ScrollModifier = "__hcenter"  // Align horizontally centered.
+	ModifierHEnd     ScrollModifier = "__hend"     // Align to the right.
+	ModifierHNearest ScrollModifier = "__hnearest" // Align horizontally to the nearest edge.
+	ModifierVStart   ScrollModifier = "__vstart"   // Align to the top.
+	ModifierVCenter  ScrollModifier = "__vcenter"  // Align vertically centered.
+	ModifierVEnd     ScrollModifier = "__vend"     // Align to the bottom.
+	ModifierVNearest ScrollModifier = "__vnearest" // Align vertically to the nearest edge.
+	ModifierFocus    ScrollModifier = "__focus"    // Focus the element after scrolling.
+)
+
+const (
+	ModifierCamel  CaseModifier = ".camel"  // Camel case: myEvent
+	ModifierKebab  CaseModifier = ".kebab"  // Kebab case: my-event
+	ModifierPascal CaseModifier = ".pascal" // Pascal case: MyEvent
+	ModifierSnake  CaseModifier = ".snake"  // Snake case: my_event
+
+	ModifierEase      EasingModifier = ".ease"
+	ModifierEaseIn    EasingModifier = ".ease-in"
+	ModifierEaseInOut EasingModifier = ".ease-in-out"
+	ModifierEaseOut   EasingModifier = ".ease-out"
+	ModifierLinear    EasingModifier = ".linear"
+
+	ModifierLeading    TimingModifier = ".leading"
+	ModifierNoLeading  TimingModifier = ".noleading"
+	ModifierNoTrailing TimingModifier = ".notrailing"
+	ModifierTrailing   TimingModifier = ".trailing"
+)
+
+// Duration outputs millisecond values for durations, rounded to the nearest millisecond.
+// Panics if the duration is negative.
+func Duration(d time.Duration) TimingModifier {
+	if d < 0 {
+		panic(fmt.Sprintf("duration must not be negative, but is: %v", d))
+	}
+	return TimingModifier(fmt.Sprintf(".%vms", d.Round(time.Millisecond).Milliseconds()))
+}
+
+// Threshold outputs a visibility percentage threshold for the __threshold modifier.
+// The value must be between 0.0 (exclusive) and 1.0 (inclusive).
+// For values less than 1.0, the value is rounded to two decimal places (e.g., 0.25 for 25% visibility).
+// For the value 1.0, it is formatted as ".100" representing 100% visibility.
+// Panics if the threshold is outside the valid range.
+func Threshold(threshold float64) VisibilityModifier {
+	if threshold <= 0 || threshold > 1 {
+		panic(fmt.Sprintf("threshold must be between 0.0 (exclusive) and 1.0 (inclusive), but is: %v", threshold))
+	}
+	// Special case: 1 represents 100% visibility
+	if threshold == 1 {
+		return VisibilityModifier(".100")
+	}
+	// Round to 2 decimal places and remove leading "0"
+	return VisibilityModifier(strings.TrimPrefix(fmt.Sprintf("%.2f", threshold), "0"))
+}
+
+func (m EventModifier) String() string { return string(m) }
+func (EventModifier) isOnModifier()    {}
+
+func (m CaseModifier) String() string     { return string(m) }
+func (CaseModifier) isOnModifier()        {}
+func (CaseModifier) isIndicatorModifier() {}
+func (CaseModifier) isRefModifier()       {}
+func (CaseModifier) isSignalsModifier()   {}
+func (CaseModifier) isBindModifier()      {}
+func (CaseModifier) isClassModifier()     {}
+func (CaseModifier) isComputedModifier()  {}
+
+func (m OnceModifier) String() string       { return string(m) }
+func (OnceModifier) isOnModifier()          {}
+func (OnceModifier) isOnIntersectModifier() {}
+
+func (m RateLimitModifier) String() string         { return string(m) }
+func (RateLimitModifier) isOnModifier()            {}
+func (RateLimitModifier) isOnIntersectModifier()   {}
+func (RateLimitModifier) isOnSignalPatchModifier() {}
+func (RateLimitModifier) isOnRAFModifier()         {}
+func (RateLimitModifier) isOnResizeModifier()      {}
+
+func (m DelayModifier) String() string         { return string(m) }
+func (DelayModifier) isOnModifier()            {}
+func (DelayModifier) isOnIntersectModifier()   {}
+func (DelayModifier) isOnSignalPatchModifier() {}
+func (DelayModifier) isInitModifier()          {}
+func (DelayModifier) isAnimateModifier()       {}
+
+func (m TimingModifier) String() string         { return string(m) }
+func (TimingModifier) isOnModifier()            {}
+func (TimingModifier) isOnIntersectModifier()   {}
+func (TimingModifier) isOnSignalPatchModifier() {}
+func (TimingModifier) isInitModifier()          {}
+func (TimingModifier) isOnIntervalModifier()    {}
+func (TimingModifier) isOnRAFModifier()         {}
+func (TimingModifier) isOnResizeModifier()      {}
+func (TimingModifier) isAnimateModifier()       {}
+
+func (m ViewTransitionModifier) String() string       { return string(m) }
+func (ViewTransitionModifier) isOnModifier()          {}
+func (ViewTransitionModifier) isOnIntersectModifier() {}
+func (ViewTransitionModifier) isOnIntervalModifier()  {}
+func (ViewTransitionModifier) isInitModifier()        {}
+
+func (m VisibilityModifier) String() string       { return string(m) }
+func (VisibilityModifier) isOnIntersectModifier() {}
+
+func (m IntervalModifier) String() string      { return string(m) }
+func (IntervalModifier) isOnIntervalModifier() {}
+func (IntervalModifier) isAnimateModifier()    {}
+
+func (m EasingModifier) String() string   { return string(m) }
+func (EasingModifier) isAnimateModifier() {}
+
+func (m IfMissingModifier) String() string   { return string(m) }
+func (IfMissingModifier) isSignalsModifier() {}
+
+func (m TerseModifier) String() string       { return string(m) }
+func (TerseModifier) isJSONSignalsModifier() {}
+
+func (m SelfModifier) String() string  { return string(m) }
+func (SelfModifier) isIgnoreModifier() {}
+
+func (m ScrollModifier) String() string          { return string(m) }
+func (ScrollModifier) isScrollIntoViewModifier() {}
+
+func (m StorageModifier) String() string   { return string(m) }
+func (StorageModifier) isPersistModifier() {}
+
+func (m URLModifier) String() string       { return string(m) }
+func (URLModifier) isQueryStringModifier() {}
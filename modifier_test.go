@@ -0,0 +1,35 @@
+package datastar_test
+
+import (
+	"testing"
+	"time"
+
+	. "maragu.dev/gomponents/html"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+func TestModifier(t *testing.T) {
+	t.Run("should accept case modifiers in all attributes that take a name", func(t *testing.T) {
+		var m data.Modifier = data.ModifierCamel
+
+		assert.Equal(t, `<div data-on:my-event__case.camel="$foo"></div>`, Div(data.On("my-event", "$foo", data.ModifierCase, m)))
+		assert.Equal(t, `<div data-indicator__case.camel="foo"></div>`, Div(data.Indicator("foo", data.ModifierCase, m)))
+		assert.Equal(t, `<div data-ref__case.camel="foo"></div>`, Div(data.Ref("foo", data.ModifierCase, m)))
+		assert.Equal(t, `<div data-signals__case.camel="{&#34;foo&#34;:1}"></div>`, Div(data.Signals(map[string]any{"foo": 1}, data.ModifierCase, m)))
+		assert.Equal(t, `<div data-bind:foo__case.camel></div>`, Div(data.BindKey("foo", data.ModifierCase, m)))
+		assert.Equal(t, `<div data-class:foo__case.camel="$foo"></div>`, Div(data.ClassKey("foo", "$foo", data.ModifierCase, m)))
+		assert.Equal(t, `<div data-computed:foo__case.camel="$foo"></div>`, Div(data.ComputedKey("foo", "$foo", data.ModifierCase, m)))
+	})
+
+	t.Run("should accept shared typed modifiers in each attribute they are valid for", func(t *testing.T) {
+		d := data.Duration(100 * time.Millisecond)
+
+		assert.Equal(t, `<div data-on:click__delay.100ms__viewtransition="$foo"></div>`, Div(data.On("click", "$foo", data.ModifierDelay, d, data.ModifierViewTransition)))
+		assert.Equal(t, `<div data-on-intersect__delay.100ms__viewtransition="$foo"></div>`, Div(data.OnIntersect("$foo", data.ModifierDelay, d, data.ModifierViewTransition)))
+		assert.Equal(t, `<div data-init__delay.100ms__viewtransition="$foo"></div>`, Div(data.Init("$foo", data.ModifierDelay, d, data.ModifierViewTransition)))
+		assert.Equal(t, `<div data-on-signal-patch__throttle.100ms.trailing="$foo"></div>`, Div(data.OnSignalPatch("$foo", data.ModifierThrottle, d, data.ModifierTrailing)))
+		assert.Equal(t, `<div data-on-interval__duration.100ms.leading__viewtransition="$foo"></div>`, Div(data.OnInterval("$foo", data.ModifierDuration, d, data.ModifierLeading, data.ModifierViewTransition)))
+	})
+}
@@ -0,0 +1,54 @@
+package datastar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MaxSignalsSize is the maximum size in bytes of signals read by [ReadSignals].
+const MaxSignalsSize = 1 << 20
+
+var (
+	// ErrNoSignals is returned by [ReadSignals] if the request carries no signals.
+	ErrNoSignals = errors.New("no signals in request")
+
+	// ErrSignalsTooLarge is returned by [ReadSignals] if the signals are larger than [MaxSignalsSize].
+	ErrSignalsTooLarge = fmt.Errorf("signals larger than %v bytes", MaxSignalsSize)
+)
+
+// ReadSignals decodes the signals sent by a Datastar backend action into v, like [json.Unmarshal].
+// For GET requests, the signals are read from the `datastar` query parameter.
+// For all other methods, they are read from the request body.
+//
+// Returns [ErrNoSignals] if there are no signals, [ErrSignalsTooLarge] if they exceed [MaxSignalsSize],
+// and a wrapped JSON error (such as [*json.SyntaxError] or [*json.UnmarshalTypeError]) if they cannot be decoded.
+//
+// See https://data-star.dev/guide/backend_requests
+func ReadSignals(r *http.Request, v any) error {
+	var b []byte
+	if r.Method == http.MethodGet {
+		b = []byte(r.URL.Query().Get("datastar"))
+	} else if r.Body != nil {
+		var err error
+		b, err = io.ReadAll(io.LimitReader(r.Body, MaxSignalsSize+1))
+		if err != nil {
+			return fmt.Errorf("failed to read signals: %w", err)
+		}
+	}
+
+	if len(bytes.TrimSpace(b)) == 0 {
+		return ErrNoSignals
+	}
+	if len(b) > MaxSignalsSize {
+		return ErrSignalsTooLarge
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to decode signals: %w", err)
+	}
+	return nil
+}
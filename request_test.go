@@ -0,0 +1,95 @@
+package datastar_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+type testSignals struct {
+	Foo int    `json:"foo"`
+	Bar string `json:"bar"`
+}
+
+func TestReadSignals(t *testing.T) {
+	t.Run("should read signals from the datastar query parameter on GET", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/?datastar="+url.QueryEscape(`{"foo":1,"bar":"baz"}`), nil)
+
+		var signals testSignals
+		err := data.ReadSignals(r, &signals)
+		assert.NoError(t, err)
+
+		if signals != (testSignals{Foo: 1, Bar: "baz"}) {
+			t.Fatalf("unexpected signals %+v", signals)
+		}
+	})
+
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		t.Run("should read signals from the body on "+method, func(t *testing.T) {
+			r := httptest.NewRequest(method, "/", strings.NewReader(`{"foo":1,"bar":"baz"}`))
+
+			var signals testSignals
+			err := data.ReadSignals(r, &signals)
+			assert.NoError(t, err)
+
+			if signals != (testSignals{Foo: 1, Bar: "baz"}) {
+				t.Fatalf("unexpected signals %+v", signals)
+			}
+		})
+	}
+
+	t.Run("should return ErrNoSignals if the query parameter is missing", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		err := data.ReadSignals(r, &testSignals{})
+		if !errors.Is(err, data.ErrNoSignals) {
+			t.Fatalf("expected ErrNoSignals but got %v", err)
+		}
+	})
+
+	t.Run("should return ErrNoSignals if the body is empty", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(""))
+
+		err := data.ReadSignals(r, &testSignals{})
+		if !errors.Is(err, data.ErrNoSignals) {
+			t.Fatalf("expected ErrNoSignals but got %v", err)
+		}
+	})
+
+	t.Run("should return ErrSignalsTooLarge if the body is too large", func(t *testing.T) {
+		body := `{"bar":"` + strings.Repeat("a", data.MaxSignalsSize) + `"}`
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+		err := data.ReadSignals(r, &testSignals{})
+		if !errors.Is(err, data.ErrSignalsTooLarge) {
+			t.Fatalf("expected ErrSignalsTooLarge but got %v", err)
+		}
+	})
+
+	t.Run("should return a JSON syntax error on invalid JSON", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"foo":`))
+
+		err := data.ReadSignals(r, &testSignals{})
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("expected json.SyntaxError but got %v", err)
+		}
+	})
+
+	t.Run("should return a JSON type error on mismatched types", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"foo":"bar"}`))
+
+		err := data.ReadSignals(r, &testSignals{})
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			t.Fatalf("expected json.UnmarshalTypeError but got %v", err)
+		}
+	})
+}
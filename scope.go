@@ -0,0 +1,181 @@
+package datastar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	g "maragu.dev/gomponents"
+)
+
+// SignalScope renders an element with one merged `data-signals` attribute,
+// collected from all [ScopedSignals] nodes rendered inside it.
+// This lets nested components declare the signals they need, while the page only gets one signals declaration on the root.
+//
+//	data.SignalScope(Div,
+//		Header(data.ScopedSignals(map[string]any{"menu": map[string]any{"open": false}})),
+//		Main(data.ScopedSignals(map[string]any{"search": ""})),
+//	)
+//
+// <div data-signals="{"menu":{"open":false},"search":""}"><header></header><main></main></div>
+//
+// Signals are merged in render order, and nested objects are merged recursively.
+// If two components declare the same signal path with different JSON types, rendering fails with a [*SignalConflictError].
+// If the same path is declared with the same type, the value rendered last wins, like Datastar does in the browser.
+//
+// Scopes can be nested, in which case signals go to the nearest scope.
+// Signals are only collected from nodes rendered directly into the scope, so rendering fails with [ErrNoSignalScope]
+// if a node inside it renders [ScopedSignals] into its own buffer, or if the element doesn't render its attributes directly.
+func SignalScope(element func(...g.Node) g.Node, children ...g.Node) g.Node {
+	return g.NodeFunc(func(w io.Writer) error {
+		sw := &signalScopeWriter{offset: -1}
+		if err := element(append([]g.Node{signalScopeMarker{}}, children...)...).Render(sw); err != nil {
+			return err
+		}
+		if sw.offset < 0 {
+			return errors.New("signal scope element did not render its attributes")
+		}
+
+		b := sw.Bytes()
+		if _, err := w.Write(b[:sw.offset]); err != nil {
+			return err
+		}
+		if len(sw.signals.keys) > 0 {
+			if err := SignalsOrdered(&sw.signals).Render(w); err != nil {
+				return err
+			}
+		}
+		_, err := w.Write(b[sw.offset:])
+		return err
+	})
+}
+
+// ScopedSignals declares signals in the nearest [SignalScope], instead of rendering its own attribute.
+// Outside a scope, rendering fails with [ErrNoSignalScope], so signals are never silently left out or declared twice.
+// Use [Signals] for signals that aren't part of a scope.
+// Panics if any name, including names in nested maps, is not a valid signal name. See [ValidateSignals].
+func ScopedSignals(signals map[string]any) g.Node {
+	mustValidateSignals(signals)
+	return scopedSignals(signals)
+}
+
+// ErrNoSignalScope is returned when rendering [ScopedSignals] outside a [SignalScope].
+var ErrNoSignalScope = errors.New("scoped signals must be rendered directly inside a signal scope")
+
+// SignalConflictError is returned when rendering a [SignalScope] where the same signal is declared with different JSON types.
+type SignalConflictError struct {
+	Name     string
+	Existing string
+	New      string
+}
+
+func (e *SignalConflictError) Error() string {
+	return fmt.Sprintf("conflicting types for signal %q: %v and %v", e.Name, e.Existing, e.New)
+}
+
+// signalScopeWriter collects the rendered element and its signals.
+type signalScopeWriter struct {
+	bytes.Buffer
+	offset  int
+	signals OrderedSignals
+}
+
+// signalScopeMarker records where the merged signals attribute goes.
+type signalScopeMarker struct{}
+
+func (signalScopeMarker) Render(w io.Writer) error {
+	sw, ok := w.(*signalScopeWriter)
+	if !ok {
+		return ErrNoSignalScope
+	}
+	if sw.offset < 0 {
+		sw.offset = sw.Len()
+	}
+	return nil
+}
+
+func (signalScopeMarker) Type() g.NodeType {
+	return g.AttributeType
+}
+
+type scopedSignals map[string]any
+
+func (s scopedSignals) Render(w io.Writer) error {
+	sw, ok := w.(*signalScopeWriter)
+	if !ok {
+		return ErrNoSignalScope
+	}
+	return mergeScopedSignals(&sw.signals, s, "")
+}
+
+func (scopedSignals) Type() g.NodeType {
+	return g.AttributeType
+}
+
+func mergeScopedSignals(target *OrderedSignals, signals map[string]any, path string) error {
+	// Sort the keys, so the output is the same every time
+	keys := make([]string, 0, len(signals))
+	for key := range signals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := joinPath(path, key)
+		value := signals[key]
+
+		if existing, ok := target.values[key]; ok {
+			existingKind, err := jsonKind(existing)
+			if err != nil {
+				return fmt.Errorf("failed to marshal signal %v: %w", name, err)
+			}
+			newKind, err := jsonKind(value)
+			if err != nil {
+				return fmt.Errorf("failed to marshal signal %v: %w", name, err)
+			}
+			if existingKind != newKind {
+				return &SignalConflictError{Name: name, Existing: existingKind, New: newKind}
+			}
+		}
+
+		nested, ok := value.(map[string]any)
+		if !ok {
+			target.set(key, value)
+			continue
+		}
+		nestedTarget, ok := target.values[key].(*OrderedSignals)
+		if !ok {
+			nestedTarget = &OrderedSignals{}
+			target.set(key, nestedTarget)
+		}
+		if err := mergeScopedSignals(nestedTarget, nested, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonKind returns the JSON type of the value, such as "object" or "string".
+func jsonKind(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	switch b[0] {
+	case '{':
+		return "object", nil
+	case '[':
+		return "array", nil
+	case '"':
+		return "string", nil
+	case 't', 'f':
+		return "boolean", nil
+	case 'n':
+		return "null", nil
+	default:
+		return "number", nil
+	}
+}
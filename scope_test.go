@@ -0,0 +1,140 @@
+package datastar_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	g "maragu.dev/gomponents"
+	. "maragu.dev/gomponents/html"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+func TestSignalScope(t *testing.T) {
+	t.Run("should render merged signals on the root element", func(t *testing.T) {
+		n := data.SignalScope(Div,
+			Class("app"),
+			Header(data.ScopedSignals(map[string]any{"menu": map[string]any{"open": false}})),
+			Main(
+				Div(data.ScopedSignals(map[string]any{"search": "", "menu": map[string]any{"items": 3}})),
+			),
+		)
+		assert.Equal(t, `<div data-signals="{&#34;menu&#34;:{&#34;open&#34;:false,&#34;items&#34;:3},&#34;search&#34;:&#34;&#34;}" class="app">`+
+			`<header></header><main><div></div></main></div>`, n)
+	})
+
+	t.Run("should let the last value win for the same type", func(t *testing.T) {
+		n := data.SignalScope(Div,
+			Span(data.ScopedSignals(map[string]any{"foo": 1})),
+			Span(data.ScopedSignals(map[string]any{"foo": 2})),
+		)
+		assert.Equal(t, `<div data-signals="{&#34;foo&#34;:2}"><span></span><span></span></div>`, n)
+	})
+
+	t.Run("should collect signals from the root element itself", func(t *testing.T) {
+		n := data.SignalScope(Div, data.ScopedSignals(map[string]any{"foo": 1}), g.Text("hi"))
+		assert.Equal(t, `<div data-signals="{&#34;foo&#34;:1}">hi</div>`, n)
+	})
+
+	t.Run("should not render a signals attribute without signals", func(t *testing.T) {
+		n := data.SignalScope(Div, Span())
+		assert.Equal(t, `<div><span></span></div>`, n)
+	})
+
+	t.Run("should collect signals in the nearest scope", func(t *testing.T) {
+		n := data.SignalScope(Div,
+			data.ScopedSignals(map[string]any{"outer": 1}),
+			data.SignalScope(Section, Span(data.ScopedSignals(map[string]any{"inner": 2}))),
+		)
+		assert.Equal(t, `<div data-signals="{&#34;outer&#34;:1}">`+
+			`<section data-signals="{&#34;inner&#34;:2}"><span></span></section></div>`, n)
+	})
+
+	t.Run("should return an error for conflicting types", func(t *testing.T) {
+		n := data.SignalScope(Div,
+			Span(data.ScopedSignals(map[string]any{"user": map[string]any{"name": "Jane"}})),
+			Span(data.ScopedSignals(map[string]any{"user": map[string]any{"name": 42}})),
+		)
+		err := n.Render(&strings.Builder{})
+		var conflictErr *data.SignalConflictError
+		if !errors.As(err, &conflictErr) {
+			t.Fatalf("expected SignalConflictError, got %v", err)
+		}
+		assert.EqualString(t, `conflicting types for signal "user.name": string and number`, err.Error())
+	})
+
+	t.Run("should return an error when an object conflicts with a value", func(t *testing.T) {
+		n := data.SignalScope(Div,
+			Span(data.ScopedSignals(map[string]any{"user": "Jane"})),
+			Span(data.ScopedSignals(map[string]any{"user": map[string]any{"name": "Jane"}})),
+		)
+		err := n.Render(&strings.Builder{})
+		assert.Error(t, err)
+		assert.EqualString(t, `conflicting types for signal "user": string and object`, err.Error())
+	})
+
+	t.Run("should return an error outside a scope", func(t *testing.T) {
+		n := Div(data.ScopedSignals(map[string]any{"foo": 1}))
+		err := n.Render(&strings.Builder{})
+		if !errors.Is(err, data.ErrNoSignalScope) {
+			t.Fatalf("expected ErrNoSignalScope, got %v", err)
+		}
+	})
+
+	t.Run("should return an error for signals rendered into another writer inside a scope", func(t *testing.T) {
+		buffered := g.NodeFunc(func(w io.Writer) error {
+			var b strings.Builder
+			if err := Span(data.ScopedSignals(map[string]any{"foo": 1})).Render(&b); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, b.String())
+			return err
+		})
+		err := data.SignalScope(Div, buffered).Render(&strings.Builder{})
+		if !errors.Is(err, data.ErrNoSignalScope) {
+			t.Fatalf("expected ErrNoSignalScope, got %v", err)
+		}
+	})
+
+	t.Run("should return an error if the element renders its attributes into another writer", func(t *testing.T) {
+		buffered := func(children ...g.Node) g.Node {
+			return g.NodeFunc(func(w io.Writer) error {
+				var b strings.Builder
+				if err := Div(children...).Render(&b); err != nil {
+					return err
+				}
+				_, err := io.WriteString(w, b.String())
+				return err
+			})
+		}
+		err := data.SignalScope(buffered, Span()).Render(&strings.Builder{})
+		if !errors.Is(err, data.ErrNoSignalScope) {
+			t.Fatalf("expected ErrNoSignalScope, got %v", err)
+		}
+	})
+
+	t.Run("should panic on invalid signal names", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		data.ScopedSignals(map[string]any{"foo__bar": 1})
+	})
+}
+
+func ExampleSignalScope() {
+	menu := func() g.Node {
+		return Nav(data.ScopedSignals(map[string]any{"menu": map[string]any{"open": false}}))
+	}
+	search := func() g.Node {
+		return Input(data.ScopedSignals(map[string]any{"query": ""}), data.Bind("query"))
+	}
+
+	fmt.Print(data.SignalScope(Body, menu(), search()))
+	// Output: <body data-signals="{&#34;menu&#34;:{&#34;open&#34;:false},&#34;query&#34;:&#34;&#34;}"><nav></nav><input data-bind="query"></body>
+}
@@ -0,0 +1,111 @@
+package datastar
+
+import (
+	"strings"
+
+	g "maragu.dev/gomponents"
+)
+
+// Signal is a typed handle to a signal, declared once with a name and a default value,
+// and then used to create attributes and expressions that refer to it.
+// The name can be a dot-separated path for nested signals, such as "user.name".
+//
+//	var count = data.NewSignal("count", 0)
+//
+//	Div(data.DeclareSignals(count),
+//		Button(data.On("click", data.Assign(count.Expr(), data.Add(count.Expr(), data.Lit(1))))),
+//		Span(count.Text()),
+//	)
+type Signal[T any] struct {
+	name  string
+	value T
+}
+
+// NewSignal declares a [Signal] with the given name and default value.
+// Panics if the name is not a valid signal name. See [ValidateSignalName].
+func NewSignal[T any](name string, value T) Signal[T] {
+	mustValidateSignalName(name)
+	return Signal[T]{name: name, value: value}
+}
+
+// Name of the signal, without the $ prefix.
+func (s Signal[T]) Name() string {
+	return s.name
+}
+
+// Default value of the signal.
+func (s Signal[T]) Default() T {
+	return s.value
+}
+
+// Expr returns an expression referring to the signal, such as "$count". See [Sig].
+func (s Signal[T]) Expr() Expr {
+	return Expr{code: "$" + s.name, prec: precCall}
+}
+
+// Set returns an expression that sets the signal to the given value, such as "$count = 1".
+// The value is encoded with [JS]. Panics if the value cannot be encoded.
+func (s Signal[T]) Set(value T) Expr {
+	return Assign(s.Expr(), Lit(value))
+}
+
+// Bind the signal to the element's value. See [Bind].
+func (s Signal[T]) Bind() g.Node {
+	return Bind(s.name)
+}
+
+// Text binds the text content of the element to the signal. See [Text].
+func (s Signal[T]) Text() g.Node {
+	return Text(s.Expr())
+}
+
+// Show the element when the signal is truthy. See [Show].
+func (s Signal[T]) Show() g.Node {
+	return Show(s.Expr())
+}
+
+// Indicator sets the signal to true while a fetch request is in flight. See [Indicator].
+func (s Signal[T]) Indicator() g.Node {
+	return Indicator(s.name)
+}
+
+// Ref sets the signal to a reference to the element. See [Ref].
+func (s Signal[T]) Ref() g.Node {
+	return Ref(s.name)
+}
+
+// SignalDeclaration is a signal with a default value, such as a [Signal].
+type SignalDeclaration interface {
+	Name() string
+	defaultValue() any
+}
+
+func (s Signal[T]) defaultValue() any {
+	return s.value
+}
+
+// DeclareSignals creates a [Signals] attribute with the default values of the given signals.
+// Signals with dot-separated names are nested.
+//
+// <div data-signals="{"count":0,"user":{"name":""}}"></div>
+func DeclareSignals(signals ...SignalDeclaration) g.Node {
+	values := map[string]any{}
+	for _, s := range signals {
+		setPath(values, s.Name(), s.defaultValue())
+	}
+	return Signals(values)
+}
+
+// setPath sets the value at the dot-separated path in m, creating nested maps as needed.
+func setPath(m map[string]any, path string, value any) {
+	parts := strings.Split(path, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[part] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+}
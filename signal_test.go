@@ -0,0 +1,85 @@
+package datastar_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "maragu.dev/gomponents/html"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+func TestSignal(t *testing.T) {
+	count := data.NewSignal("count", 0)
+	name := data.NewSignal("user.name", "")
+	loading := data.NewSignal("loading", false)
+
+	t.Run("should have name and default value", func(t *testing.T) {
+		assert.EqualString(t, "count", count.Name())
+		if count.Default() != 0 {
+			t.Fatalf("expected default value 0 but got %v", count.Default())
+		}
+	})
+
+	t.Run("should output $count as expression", func(t *testing.T) {
+		assert.EqualString(t, "$count", count.Expr().String())
+		assert.EqualString(t, "$user.name", name.Expr().String())
+	})
+
+	t.Run("should output assignment with JavaScript literal", func(t *testing.T) {
+		assert.EqualString(t, "$count = 1", count.Set(1).String())
+		assert.EqualString(t, `$user.name = 'O\'Brien'`, name.Set("O'Brien").String())
+	})
+
+	t.Run("should encode values like the declared signals", func(t *testing.T) {
+		timeout := data.NewSignal("timeout", 5*time.Second)
+		assert.Equal(t, `<div data-signals="{&#34;timeout&#34;:5000000000}"></div>`, Div(data.DeclareSignals(timeout)))
+		assert.EqualString(t, "$timeout = 5000000000", timeout.Set(5*time.Second).String())
+	})
+
+	t.Run("should compose with other expressions", func(t *testing.T) {
+		assert.Equal(t, `<button data-on:click="$count = $count + 1"></button>`,
+			Button(data.On("click", data.Assign(count.Expr(), data.Add(count.Expr(), data.Lit(1))))))
+	})
+
+	t.Run(`should output data-bind="user.name"`, func(t *testing.T) {
+		assert.Equal(t, `<input data-bind="user.name">`, Input(name.Bind()))
+	})
+
+	t.Run(`should output data-text="$count"`, func(t *testing.T) {
+		assert.Equal(t, `<span data-text="$count"></span>`, Span(count.Text()))
+	})
+
+	t.Run(`should output data-show="$loading"`, func(t *testing.T) {
+		assert.Equal(t, `<div data-show="$loading"></div>`, Div(loading.Show()))
+	})
+
+	t.Run(`should output data-indicator="loading"`, func(t *testing.T) {
+		assert.Equal(t, `<button data-indicator="loading"></button>`, Button(loading.Indicator()))
+	})
+
+	t.Run(`should output data-ref="count"`, func(t *testing.T) {
+		assert.Equal(t, `<div data-ref="count"></div>`, Div(count.Ref()))
+	})
+}
+
+func TestDeclareSignals(t *testing.T) {
+	t.Run("should output data-signals with default values", func(t *testing.T) {
+		n := Div(data.DeclareSignals(data.NewSignal("count", 0), data.NewSignal("loading", false)))
+		assert.Equal(t, `<div data-signals="{&#34;count&#34;:0,&#34;loading&#34;:false}"></div>`, n)
+	})
+
+	t.Run("should nest dot-separated names", func(t *testing.T) {
+		n := Div(data.DeclareSignals(data.NewSignal("user.name", "Jane"), data.NewSignal("user.age", 42)))
+		assert.Equal(t, `<div data-signals="{&#34;user&#34;:{&#34;age&#34;:42,&#34;name&#34;:&#34;Jane&#34;}}"></div>`, n)
+	})
+}
+
+func ExampleSignal() {
+	count := data.NewSignal("count", 0)
+
+	fmt.Print(Div(data.DeclareSignals(count), Button(data.On("click", count.Set(0))), Span(count.Text())))
+	// Output: <div data-signals="{&#34;count&#34;:0}"><button data-on:click="$count = 0"></button><span data-text="$count"></span></div>
+}
@@ -0,0 +1,424 @@
+package datastar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	g "maragu.dev/gomponents"
+)
+
+// SignalsFrom is like [Signals], but creates the signals from the exported fields of a struct, in field order.
+//
+// Field names are taken from `json` struct tags like with [json.Marshal], including `json:"-"` and `omitempty`.
+// Nested structs become nested signals, and embedded structs have their fields promoted, following the same rules as
+// [json.Marshal] for embedded pointers, unexported embedded types, and fields with the same name.
+// Other `json` tag options, such as `string`, are not supported.
+// The `datastar` struct tag supports these options:
+//
+//   - `datastar:"-"` skips the field.
+//   - `datastar:"local"` prefixes the signal name with an underscore, so it is not sent to the backend by default.
+//
+// For example:
+//
+//	type Form struct {
+//		Name    string `json:"name"`
+//		Address struct {
+//			City string `json:"city"`
+//		} `json:"address"`
+//		Open bool `json:"open" datastar:"local"`
+//	}
+//
+// <div data-signals="{"name":"","address":{"city":""},"_open":false}"></div>
+//
+// Panics if v is not a struct or a pointer to one, if a field name is not a valid signal name (see [ValidateSignalName]),
+// or if a field value cannot be marshalled to JSON.
+func SignalsFrom(v any, modifiers ...SignalsModifier) g.Node {
+	nameWithModifiers := ""
+	for _, modifier := range modifiers {
+		nameWithModifiers += modifier.String()
+	}
+
+	var b bytes.Buffer
+	if err := writeStructSignals(&b, reflect.ValueOf(v), ""); err != nil {
+		panic(err.Error())
+	}
+	return data("signals"+nameWithModifiers, b.String())
+}
+
+func writeStructSignals(b *bytes.Buffer, v reflect.Value, path string) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		if !v.IsValid() {
+			return fmt.Errorf("signals must be a struct, but is nil")
+		}
+		return fmt.Errorf("signals must be a struct, but is %v", v.Type())
+	}
+
+	b.WriteString("{")
+	first := true
+	for _, field := range signalFields(v.Type()) {
+		fv, ok := fieldByIndex(v, field.index)
+		if !ok || (field.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+
+		name := joinPath(path, field.name)
+		if reason := validateSignalNameSegment(field.name); reason != "" {
+			return &SignalNameError{Name: name, Reason: reason}
+		}
+
+		if !first {
+			b.WriteString(",")
+		}
+		first = false
+
+		key, err := json.Marshal(field.name)
+		if err != nil {
+			return err
+		}
+		b.Write(key)
+		b.WriteString(":")
+
+		if isSignalsStruct(fv) {
+			if err := writeStructSignals(b, fv, name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, err := marshalSignals(fv.Interface())
+		if err != nil {
+			return fmt.Errorf("failed to marshal signal %v: %w", name, err)
+		}
+		b.Write(value)
+	}
+	b.WriteString("}")
+
+	return nil
+}
+
+// isEmptyValue reports whether v is empty as defined by `omitempty` in [json.Marshal]:
+// false, 0, a nil pointer or interface, and an empty array, slice, map, or string.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// isSignalsStruct reports whether v is a struct (or pointer to one) that should become nested signals,
+// instead of being marshalled to JSON as a single value.
+func isSignalsStruct(v reflect.Value) bool {
+	t := v.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	return !t.Implements(jsonMarshalerType) && !reflect.PointerTo(t).Implements(jsonMarshalerType)
+}
+
+type signalField struct {
+	index     []int
+	name      string
+	tagged    bool
+	omitEmpty bool
+}
+
+// signalFields of the struct type t, in field order, with embedded struct fields promoted.
+// Like [json.Marshal], embedded structs can be pointers or of unexported types, and when several fields have the same name,
+// the least nested one wins, then the one with a name from a `json` tag. If that still leaves more than one, all of them are skipped.
+func signalFields(t reflect.Type) []signalField {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var fields []signalField
+	current, next := []embedded{}, []embedded{{typ: t}}
+	visited := map[reflect.Type]bool{}
+	for len(next) > 0 {
+		current, next = next, nil
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+
+			for i := 0; i < e.typ.NumField(); i++ {
+				f := e.typ.Field(i)
+
+				ft := f.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if f.Anonymous {
+					if !f.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !f.IsExported() {
+					continue
+				}
+
+				skip, local := false, false
+				for _, opt := range strings.Split(f.Tag.Get("datastar"), ",") {
+					switch opt {
+					case "-":
+						skip = true
+					case "local":
+						local = true
+					}
+				}
+
+				jsonName, jsonOpts, _ := strings.Cut(f.Tag.Get("json"), ",")
+				if skip || (jsonName == "-" && jsonOpts == "") {
+					continue
+				}
+
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+
+				if f.Anonymous && jsonName == "" && ft.Kind() == reflect.Struct {
+					next = append(next, embedded{typ: ft, index: index})
+					continue
+				}
+
+				name := jsonName
+				if name == "" {
+					name = f.Name
+				}
+				if local {
+					name = "_" + name
+				}
+
+				fields = append(fields, signalField{
+					index:     index,
+					name:      name,
+					tagged:    jsonName != "",
+					omitEmpty: strings.Contains(","+jsonOpts+",", ",omitempty,"),
+				})
+			}
+		}
+		// Mark types as visited after the whole level, so the same type embedded twice at one level conflicts
+		for _, e := range current {
+			visited[e.typ] = true
+		}
+	}
+
+	byName := map[string][]signalField{}
+	var names []string
+	for _, f := range fields {
+		if _, ok := byName[f.name]; !ok {
+			names = append(names, f.name)
+		}
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	var dominant []signalField
+	for _, name := range names {
+		if f, ok := dominantField(byName[name]); ok {
+			dominant = append(dominant, f)
+		}
+	}
+	sort.Slice(dominant, func(i, j int) bool {
+		return lessIndex(dominant[i].index, dominant[j].index)
+	})
+	return dominant
+}
+
+// dominantField of the fields with the same name: the least nested one, then the tagged one.
+// Reports false if there is no single such field.
+func dominantField(fields []signalField) (signalField, bool) {
+	depth := len(fields[0].index)
+	for _, f := range fields {
+		if len(f.index) < depth {
+			depth = len(f.index)
+		}
+	}
+
+	var candidates []signalField
+	for _, f := range fields {
+		if len(f.index) == depth {
+			candidates = append(candidates, f)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+
+	var tagged []signalField
+	for _, f := range candidates {
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+	return signalField{}, false
+}
+
+// lessIndex reports whether the field index sequence a comes before b in field order.
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// fieldByIndex is like [reflect.Value.FieldByIndex], but reports false instead of panicking on nil embedded struct pointers.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// OrderedSignals are signals that keep the order in which they are set, so they always render to the same JSON.
+// This makes it safe to merge signals from several components and still get byte-identical HTML,
+// for example in golden file tests. The zero value is ready to use.
+// It marshals to JSON both as a value and a pointer, but must be passed as a pointer to be changed.
+//
+// Use it with [SignalsOrdered], or pass it to [SSE.PatchSignals].
+type OrderedSignals struct {
+	keys   []string
+	values map[string]any
+}
+
+// Set the signal with the given name to the value and return the signals, so calls can be chained.
+// The name can be a dot-separated path such as "user.name", which sets a nested signal.
+// Setting an existing signal keeps its original position.
+// The value is stored as is, so maps and slices are shared with the caller, and must not be changed afterwards.
+// Panics if the name is not a valid signal name. See [ValidateSignalName].
+func (s *OrderedSignals) Set(name string, value any) *OrderedSignals {
+	mustValidateSignalName(name)
+	path := strings.Split(name, ".")
+	target := s
+	for _, segment := range path[:len(path)-1] {
+		nested, ok := target.values[segment].(*OrderedSignals)
+		if !ok {
+			nested = &OrderedSignals{}
+			target.set(segment, nested)
+		}
+		target = nested
+	}
+	target.set(path[len(path)-1], value)
+	return s
+}
+
+// Merge the other signals into s and return s.
+// Values from later signals win, nested [OrderedSignals] are merged recursively,
+// and new signals are added after existing ones in the order they appear.
+// Like with [OrderedSignals.Set], other values are stored as is, so maps and slices are shared with the other signals.
+func (s *OrderedSignals) Merge(others ...*OrderedSignals) *OrderedSignals {
+	for _, other := range others {
+		if other == nil {
+			continue
+		}
+		for _, key := range other.keys {
+			value := other.values[key]
+			if nested, ok := value.(*OrderedSignals); ok {
+				existing, ok := s.values[key].(*OrderedSignals)
+				if !ok {
+					existing = &OrderedSignals{}
+					s.set(key, existing)
+				}
+				existing.Merge(nested)
+				continue
+			}
+			s.set(key, value)
+		}
+	}
+	return s
+}
+
+// Keys of the top-level signals, in order.
+func (s *OrderedSignals) Keys() []string {
+	return append([]string(nil), s.keys...)
+}
+
+// MarshalJSON outputs the signals as a JSON object, with keys in order.
+func (s OrderedSignals) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("{")
+	for i, key := range s.keys {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteString(":")
+		v, err := json.Marshal(s.values[key])
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal signal %v: %w", key, err)
+		}
+		b.Write(v)
+	}
+	b.WriteString("}")
+	return b.Bytes(), nil
+}
+
+func (s *OrderedSignals) set(key string, value any) {
+	if s.values == nil {
+		s.values = map[string]any{}
+	}
+	if _, ok := s.values[key]; !ok {
+		s.keys = append(s.keys, key)
+	}
+	s.values[key] = value
+}
+
+// SignalsOrdered is like [Signals], but outputs the signals in the order they were set. See [OrderedSignals].
+//
+//	var s data.OrderedSignals
+//	s.Set("foo", 1).Set("bar.baz", true)
+//
+// <div data-signals="{"foo":1,"bar":{"baz":true}}"></div>
+//
+// Panics if a value cannot be marshalled to JSON.
+func SignalsOrdered(signals *OrderedSignals, modifiers ...SignalsModifier) g.Node {
+	nameWithModifiers := ""
+	for _, modifier := range modifiers {
+		nameWithModifiers += modifier.String()
+	}
+	if signals == nil {
+		signals = &OrderedSignals{}
+	}
+	return data("signals"+nameWithModifiers, toSignals(signals))
+}
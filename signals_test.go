@@ -0,0 +1,344 @@
+package datastar_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "maragu.dev/gomponents/html"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+type testAddress struct {
+	City string `json:"city"`
+}
+
+type Timestamps struct {
+	Created time.Time `json:"created"`
+}
+
+type form struct {
+	Timestamps
+	Name     string       `json:"name"`
+	Email    string       `json:"email,omitempty"`
+	Address  testAddress  `json:"address"`
+	Previous *testAddress `json:"previous"`
+	Open     bool         `json:"open" datastar:"local"`
+	Secret   string       `datastar:"-"`
+	Ignored  string       `json:"-"`
+	Tags     []string     `json:"tags"`
+	Plain    int
+	internal string
+}
+
+func TestSignalsFrom(t *testing.T) {
+	t.Run("should output signals in field order", func(t *testing.T) {
+		n := Div(data.SignalsFrom(form{Name: "Jane", Address: testAddress{City: "Copenhagen"}, Tags: []string{"a"}}))
+		assert.Equal(t, `<div data-signals="{`+
+			`&#34;created&#34;:&#34;0001-01-01T00:00:00Z&#34;,`+
+			`&#34;name&#34;:&#34;Jane&#34;,`+
+			`&#34;address&#34;:{&#34;city&#34;:&#34;Copenhagen&#34;},`+
+			`&#34;previous&#34;:null,`+
+			`&#34;_open&#34;:false,`+
+			`&#34;tags&#34;:[&#34;a&#34;],`+
+			`&#34;Plain&#34;:0}"></div>`, n)
+	})
+
+	t.Run("should output non-empty omitempty fields and nested struct pointers", func(t *testing.T) {
+		n := Div(data.SignalsFrom(&struct {
+			Email    string       `json:"email,omitempty"`
+			Previous *testAddress `json:"previous"`
+		}{Email: "jane@example.com", Previous: &testAddress{City: "Aarhus"}}))
+		assert.Equal(t, `<div data-signals="{&#34;email&#34;:&#34;jane@example.com&#34;,&#34;previous&#34;:{&#34;city&#34;:&#34;Aarhus&#34;}}"></div>`, n)
+	})
+
+	t.Run("should promote fields of embedded struct pointers, and skip them if nil", func(t *testing.T) {
+		type Extra struct {
+			Note string `json:"note"`
+		}
+		type withExtra struct {
+			*Extra
+			Name string `json:"name"`
+		}
+
+		n := Div(data.SignalsFrom(withExtra{Extra: &Extra{Note: "hi"}, Name: "Jane"}))
+		assert.Equal(t, `<div data-signals="{&#34;note&#34;:&#34;hi&#34;,&#34;name&#34;:&#34;Jane&#34;}"></div>`, n)
+
+		n = Div(data.SignalsFrom(withExtra{Name: "Jane"}))
+		assert.Equal(t, `<div data-signals="{&#34;name&#34;:&#34;Jane&#34;}"></div>`, n)
+	})
+
+	t.Run("should promote exported fields of embedded unexported structs", func(t *testing.T) {
+		type extra struct {
+			Note     string `json:"note"`
+			internal string
+		}
+		n := Div(data.SignalsFrom(struct {
+			extra
+		}{extra: extra{Note: "hi"}}))
+		assert.Equal(t, `<div data-signals="{&#34;note&#34;:&#34;hi&#34;}"></div>`, n)
+	})
+
+	t.Run("should prefer the least nested field on name collisions", func(t *testing.T) {
+		type Person struct {
+			Name string `json:"name"`
+			City string `json:"city"`
+		}
+		n := Div(data.SignalsFrom(struct {
+			Person
+			Name string `json:"name"`
+		}{Person: Person{Name: "inner", City: "Aarhus"}, Name: "outer"}))
+		assert.Equal(t, `<div data-signals="{&#34;city&#34;:&#34;Aarhus&#34;,&#34;name&#34;:&#34;outer&#34;}"></div>`, n)
+	})
+
+	t.Run("should prefer the tagged field on name collisions at the same depth", func(t *testing.T) {
+		type Tagged struct {
+			Value string `json:"Value"`
+		}
+		type Untagged struct {
+			Value string
+		}
+		n := Div(data.SignalsFrom(struct {
+			Untagged
+			Tagged
+		}{Untagged: Untagged{Value: "untagged"}, Tagged: Tagged{Value: "tagged"}}))
+		assert.Equal(t, `<div data-signals="{&#34;Value&#34;:&#34;tagged&#34;}"></div>`, n)
+	})
+
+	t.Run("should skip fields with conflicting names at the same depth", func(t *testing.T) {
+		type First struct {
+			Value string
+		}
+		type Second struct {
+			Value string
+			Other string `json:"other"`
+		}
+		n := Div(data.SignalsFrom(struct {
+			First
+			Second
+		}{First: First{Value: "first"}, Second: Second{Value: "second", Other: "other"}}))
+		assert.Equal(t, `<div data-signals="{&#34;other&#34;:&#34;other&#34;}"></div>`, n)
+	})
+
+	t.Run("should output the same field names as json.Marshal", func(t *testing.T) {
+		type Tagged struct {
+			Value string `json:"Value"`
+		}
+		type Untagged struct {
+			Value string
+			Other string
+		}
+		type Extra struct {
+			Note string `json:"note"`
+		}
+		v := struct {
+			Untagged
+			Tagged
+			*Extra
+			Name string `json:"name"`
+		}{Extra: &Extra{Note: "hi"}, Name: "Jane"}
+
+		expected, err := json.Marshal(v)
+		assert.NoError(t, err)
+		assert.Equal(t, `<div data-signals="`+html.EscapeString(string(expected))+`"></div>`, Div(data.SignalsFrom(v)))
+	})
+
+	t.Run("should omit empty values like json.Marshal", func(t *testing.T) {
+		type Inner struct {
+			A int `json:"a"`
+		}
+		v := struct {
+			Tags    []string       `json:"tags,omitempty"`
+			Labels  map[string]int `json:"labels,omitempty"`
+			Array   [0]int         `json:"array,omitempty"`
+			Count   int            `json:"count,omitempty"`
+			Open    bool           `json:"open,omitempty"`
+			Name    string         `json:"name,omitempty"`
+			Pointer *Inner         `json:"pointer,omitempty"`
+			Any     any            `json:"any,omitempty"`
+			Inner   Inner          `json:"inner,omitempty"`
+			Full    []string       `json:"full,omitempty"`
+		}{Tags: []string{}, Labels: map[string]int{}, Full: []string{"a"}}
+
+		expected, err := json.Marshal(v)
+		assert.NoError(t, err)
+		assert.EqualString(t, `{"inner":{"a":0},"full":["a"]}`, string(expected))
+		assert.Equal(t, `<div data-signals="`+html.EscapeString(string(expected))+`"></div>`, Div(data.SignalsFrom(v)))
+	})
+
+	t.Run("should output modifiers", func(t *testing.T) {
+		n := Div(data.SignalsFrom(testAddress{}, data.ModifierIfMissing))
+		assert.Equal(t, `<div data-signals__ifmissing="{&#34;city&#34;:&#34;&#34;}"></div>`, n)
+	})
+
+	t.Run("should panic on double underscores in names", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic for double underscore")
+			}
+		}()
+		data.SignalsFrom(struct {
+			Foo string `json:"foo__bar"`
+		}{})
+	})
+
+	t.Run("should panic on local names with a leading underscore", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic for double underscore")
+			}
+		}()
+		data.SignalsFrom(struct {
+			Foo string `json:"_foo" datastar:"local"`
+		}{})
+	})
+
+	t.Run("should panic on non-structs", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic for non-struct")
+			}
+		}()
+		data.SignalsFrom(map[string]any{"foo": 1})
+	})
+}
+
+func TestOrderedSignals(t *testing.T) {
+	t.Run("should output signals in the order they were set", func(t *testing.T) {
+		var s data.OrderedSignals
+		s.Set("zoo", 1).Set("bar", "a").Set("foo", true)
+		assert.Equal(t, `<div data-signals="{&#34;zoo&#34;:1,&#34;bar&#34;:&#34;a&#34;,&#34;foo&#34;:true}"></div>`, Div(data.SignalsOrdered(&s)))
+	})
+
+	t.Run("should keep the original position when setting an existing signal", func(t *testing.T) {
+		var s data.OrderedSignals
+		s.Set("foo", 1).Set("bar", 2).Set("foo", 3)
+		assert.Equal(t, `<div data-signals="{&#34;foo&#34;:3,&#34;bar&#34;:2}"></div>`, Div(data.SignalsOrdered(&s)))
+	})
+
+	t.Run("should set nested signals from dotted names", func(t *testing.T) {
+		var s data.OrderedSignals
+		s.Set("user.name", "Jane").Set("open", false).Set("user.age", 42)
+		assert.Equal(t, `<div data-signals="{&#34;user&#34;:{&#34;name&#34;:&#34;Jane&#34;,&#34;age&#34;:42},&#34;open&#34;:false}"></div>`, Div(data.SignalsOrdered(&s)))
+	})
+
+	t.Run("should merge recursively, with later values winning", func(t *testing.T) {
+		var a, b, c data.OrderedSignals
+		a.Set("foo", 1).Set("user.name", "Jane")
+		b.Set("bar", 2).Set("user.name", "John").Set("user.age", 42)
+		c.Set("foo", 3)
+
+		s := (&data.OrderedSignals{}).Merge(&a, &b, &c)
+		assert.Equal(t, `<div data-signals="{&#34;foo&#34;:3,&#34;user&#34;:{&#34;name&#34;:&#34;John&#34;,&#34;age&#34;:42},&#34;bar&#34;:2}"></div>`, Div(data.SignalsOrdered(s)))
+	})
+
+	t.Run("should not change the merged signals", func(t *testing.T) {
+		var a, b data.OrderedSignals
+		b.Set("user.name", "John")
+		a.Merge(&b)
+		a.Set("user.name", "Jane")
+
+		b2, err := b.MarshalJSON()
+		assert.NoError(t, err)
+		assert.EqualString(t, `{"user":{"name":"John"}}`, string(b2))
+	})
+
+	t.Run("should render byte-identical output every time", func(t *testing.T) {
+		build := func() string {
+			var s data.OrderedSignals
+			for _, name := range []string{"c", "a", "b", "d", "e", "f"} {
+				s.Set(name, map[string]any{"y": 1, "x": 2})
+			}
+			return fmt.Sprint(Div(data.SignalsOrdered(&s)))
+		}
+		expected := build()
+		for i := 0; i < 10; i++ {
+			assert.EqualString(t, expected, build())
+		}
+	})
+
+	t.Run("should marshal the same by value and by pointer", func(t *testing.T) {
+		var s data.OrderedSignals
+		s.Set("foo", 1).Set("bar.baz", true)
+		expected := `{"foo":1,"bar":{"baz":true}}`
+
+		byValue, err := json.Marshal(s)
+		assert.NoError(t, err)
+		assert.EqualString(t, expected, string(byValue))
+
+		byPointer, err := json.Marshal(&s)
+		assert.NoError(t, err)
+		assert.EqualString(t, expected, string(byPointer))
+
+		inStruct, err := json.Marshal(struct {
+			Signals data.OrderedSignals `json:"signals"`
+		}{Signals: s})
+		assert.NoError(t, err)
+		assert.EqualString(t, `{"signals":`+expected+`}`, string(inStruct))
+
+		assert.EqualString(t, expected, data.JS(s))
+	})
+
+	t.Run("should patch signals by value", func(t *testing.T) {
+		var s data.OrderedSignals
+		s.Set("foo", 1)
+
+		w := httptest.NewRecorder()
+		sse := data.NewSSE(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.NoError(t, sse.PatchSignals(s))
+		assert.EqualString(t, "event: datastar-patch-signals\ndata: signals {\"foo\":1}\n\n", w.Body.String())
+	})
+
+	t.Run("should return keys in order", func(t *testing.T) {
+		var s data.OrderedSignals
+		s.Set("b", 1).Set("a", 2)
+		assert.EqualString(t, "[b a]", fmt.Sprint(s.Keys()))
+	})
+
+	t.Run("should output empty object for nil signals", func(t *testing.T) {
+		assert.Equal(t, `<div data-signals="{}"></div>`, Div(data.SignalsOrdered(nil)))
+	})
+
+	t.Run("should output modifiers", func(t *testing.T) {
+		var s data.OrderedSignals
+		s.Set("foo", 1)
+		assert.Equal(t, `<div data-signals__ifmissing="{&#34;foo&#34;:1}"></div>`, Div(data.SignalsOrdered(&s, data.ModifierIfMissing)))
+	})
+
+	t.Run("should panic on invalid signal name", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		var s data.OrderedSignals
+		s.Set("foo__bar", 1)
+	})
+}
+
+func ExampleSignalsFrom() {
+	type Form struct {
+		Name    string `json:"name"`
+		Address struct {
+			City string `json:"city"`
+		} `json:"address"`
+		Open bool `json:"open" datastar:"local"`
+	}
+
+	fmt.Print(Div(data.SignalsFrom(Form{Name: "Jane"})))
+	// Output: <div data-signals="{&#34;name&#34;:&#34;Jane&#34;,&#34;address&#34;:{&#34;city&#34;:&#34;&#34;},&#34;_open&#34;:false}"></div>
+}
+
+func ExampleSignalsOrdered() {
+	var s data.OrderedSignals
+	s.Set("foo", 1).Set("bar.baz", true)
+
+	fmt.Print(Div(data.SignalsOrdered(&s)))
+	// Output: <div data-signals="{&#34;foo&#34;:1,&#34;bar&#34;:{&#34;baz&#34;:true}}"></div>
+}
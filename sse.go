@@ -0,0 +1,466 @@
+package datastar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	g "maragu.dev/gomponents"
+)
+
+// Event types sent to the client.
+const (
+	EventTypePatchElements = "datastar-patch-elements"
+	EventTypePatchSignals  = "datastar-patch-signals"
+)
+
+// SSE is a Server-Sent Events writer for responding to Datastar backend actions.
+// It is safe for concurrent use.
+//
+// See https://data-star.dev/reference/sse_events
+type SSE struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	ctx     context.Context
+	mu      sync.Mutex
+}
+
+// NewSSE sets the Server-Sent Events headers on w, writes the status code, and flushes,
+// so the client knows the stream is open. Events can then be sent with the methods on the returned [SSE].
+func NewSSE(w http.ResponseWriter, r *http.Request) *SSE {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.ProtoMajor == 1 {
+		w.Header().Set("Connection", "keep-alive")
+	}
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	return &SSE{
+		w:       w,
+		flusher: flusher,
+		ctx:     r.Context(),
+	}
+}
+
+// Context returns the context of the request the [SSE] was created for.
+// It is canceled when the client disconnects.
+func (s *SSE) Context() context.Context {
+	return s.ctx
+}
+
+// PatchElements renders the node and sends it to the client in a `datastar-patch-elements` event.
+// By default, elements are morphed into the DOM by matching their IDs.
+//
+// See https://data-star.dev/reference/sse_events#datastar-patch-elements
+func (s *SSE) PatchElements(node g.Node, opts ...PatchElementsOption) error {
+	e, err := patchElementsEvent(node, opts)
+	if err != nil {
+		return err
+	}
+	return s.send(e)
+}
+
+// PatchSignals sends the signals to the client in a `datastar-patch-signals` event.
+// The signals can be a map[string]any or anything else that marshals to a JSON object,
+// and are encoded the same way as in [Signals].
+// Setting a signal's value to nil removes the signal.
+//
+// See https://data-star.dev/reference/sse_events#datastar-patch-signals
+func (s *SSE) PatchSignals(signals any, opts ...PatchSignalsOption) error {
+	e, err := patchSignalsEvent(signals, opts)
+	if err != nil {
+		return err
+	}
+	return s.send(e)
+}
+
+// ExecuteScript runs the script on the client, by appending a `<script>` element to the body in a `datastar-patch-elements` event.
+// By default, the element removes itself after running. See [WithAutoRemove] and [WithScriptAttrs].
+// The script is written as-is, and returns an error if it contains a closing </script> tag, which would end the element early.
+// Use [JS] or [Str] to embed values in the script, which escape "<".
+//
+// See https://data-star.dev/reference/sse_events#datastar-patch-elements
+func (s *SSE) ExecuteScript(script string, opts ...ExecuteScriptOption) error {
+	e, err := executeScriptEvent(script, opts)
+	if err != nil {
+		return err
+	}
+	return s.send(e)
+}
+
+// Redirect navigates the client to the URL, using [SSE.ExecuteScript].
+// The URL is encoded as a string literal, so it can't break out of the script.
+func (s *SSE) Redirect(url string, opts ...ExecuteScriptOption) error {
+	return s.ExecuteScript("setTimeout(() => window.location.href = "+quote(url)+")", opts...)
+}
+
+// ConsoleLog logs the value to the browser console, using [SSE.ExecuteScript].
+// The value is encoded like [JS], and an error is returned if it can't be.
+func (s *SSE) ConsoleLog(v any, opts ...ExecuteScriptOption) error {
+	js, err := toJS(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode console log value: %w", err)
+	}
+	return s.ExecuteScript("console.log("+js+")", opts...)
+}
+
+// DispatchCustomEvent dispatches a bubbling, cancelable, and composed [CustomEvent] with the name and detail,
+// using [SSE.ExecuteScript]. The event is dispatched on all elements matching the selector,
+// or on the document if the selector is empty. Listen for it with [On], where the detail is available as evt.detail:
+//
+//	data.On("cart-updated", "$count = evt.detail.count", data.ModifierWindow)
+//
+// The detail is encoded like [JS], and an error is returned if it can't be.
+//
+// [CustomEvent]: https://developer.mozilla.org/en-US/docs/Web/API/CustomEvent
+func (s *SSE) DispatchCustomEvent(name string, detail any, selector string, opts ...ExecuteScriptOption) error {
+	if name == "" {
+		return errors.New("event name must not be empty")
+	}
+	js, err := toJS(detail)
+	if err != nil {
+		return fmt.Errorf("failed to encode event detail: %w", err)
+	}
+
+	dispatch := "dispatchEvent(new CustomEvent(" + quote(name) + ", {bubbles: true, cancelable: true, composed: true, detail: " + js + "}))"
+	if selector == "" {
+		return s.ExecuteScript("document."+dispatch, opts...)
+	}
+	return s.ExecuteScript("document.querySelectorAll("+quote(selector)+").forEach((el) => el."+dispatch+")", opts...)
+}
+
+// RemoveElements removes the elements matching the selector from the DOM,
+// with a `datastar-patch-elements` event in [PatchModeRemove].
+// Returns an error if the selector is empty.
+//
+// See https://data-star.dev/reference/sse_events#datastar-patch-elements
+func (s *SSE) RemoveElements(selector string, opts ...PatchElementsOption) error {
+	if selector == "" {
+		return errors.New("selector must not be empty")
+	}
+	opts = append(opts, WithSelector(selector), WithMode(PatchModeRemove))
+	return s.PatchElements(nil, opts...)
+}
+
+// send writes the event to the client and flushes.
+func (s *SSE) send(e event) error {
+	var b bytes.Buffer
+	if err := e.writeTo(&b); err != nil {
+		return err
+	}
+	return s.write(b.Bytes())
+}
+
+// write a pre-rendered event frame to the client and flushes.
+func (s *SSE) write(frame []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(frame); err != nil {
+		return err
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+	return nil
+}
+
+// PatchElementsOption configures a `datastar-patch-elements` event.
+type PatchElementsOption interface {
+	applyPatchElements(*patchElementsOptions)
+}
+
+type patchElementsOptions struct {
+	eventOptions
+	selector       string
+	mode           PatchMode
+	viewTransition bool
+}
+
+type patchElementsOption func(*patchElementsOptions)
+
+func (o patchElementsOption) applyPatchElements(opts *patchElementsOptions) {
+	o(opts)
+}
+
+// applyPatchElements sets the elements to patch, instead of matching them by ID.
+func (o SelectorOption) applyPatchElements(opts *patchElementsOptions) {
+	opts.selector = string(o)
+}
+
+// PatchMode is how elements are patched into the DOM. See [WithMode].
+type PatchMode string
+
+const (
+	PatchModeOuter   PatchMode = "outer"   // Morph the outer HTML of the elements. This is the default.
+	PatchModeInner   PatchMode = "inner"   // Morph the inner HTML of the elements.
+	PatchModeReplace PatchMode = "replace" // Replace the elements, without morphing.
+	PatchModePrepend PatchMode = "prepend" // Prepend the elements to the target's children.
+	PatchModeAppend  PatchMode = "append"  // Append the elements to the target's children.
+	PatchModeBefore  PatchMode = "before"  // Insert the elements before the target as siblings.
+	PatchModeAfter   PatchMode = "after"   // Insert the elements after the target as siblings.
+	PatchModeRemove  PatchMode = "remove"  // Remove the target elements.
+)
+
+// WithMode sets how elements are patched into the DOM. Defaults to [PatchModeOuter].
+// Modes other than outer and inner usually need a target, set with [WithSelector].
+// Panics if the mode is not one of the PatchMode constants.
+func WithMode(mode PatchMode) PatchElementsOption {
+	switch mode {
+	case PatchModeOuter, PatchModeInner, PatchModeReplace, PatchModePrepend, PatchModeAppend, PatchModeBefore, PatchModeAfter, PatchModeRemove:
+	default:
+		panic(fmt.Sprintf("unknown patch mode: %q", mode))
+	}
+	return patchElementsOption(func(opts *patchElementsOptions) {
+		opts.mode = mode
+	})
+}
+
+// WithViewTransition patches the elements in a view transition, if the browser supports it.
+func WithViewTransition() PatchElementsOption {
+	return patchElementsOption(func(opts *patchElementsOptions) {
+		opts.viewTransition = true
+	})
+}
+
+// PatchSignalsOption configures a `datastar-patch-signals` event.
+type PatchSignalsOption interface {
+	applyPatchSignals(*patchSignalsOptions)
+}
+
+type patchSignalsOptions struct {
+	eventOptions
+	onlyIfMissing bool
+}
+
+type patchSignalsOption func(*patchSignalsOptions)
+
+func (o patchSignalsOption) applyPatchSignals(opts *patchSignalsOptions) {
+	o(opts)
+}
+
+// WithOnlyIfMissing only patches signals that don't already exist on the client.
+func WithOnlyIfMissing() PatchSignalsOption {
+	return patchSignalsOption(func(opts *patchSignalsOptions) {
+		opts.onlyIfMissing = true
+	})
+}
+
+// ExecuteScriptOption configures the event sent by [SSE.ExecuteScript].
+type ExecuteScriptOption interface {
+	applyExecuteScript(*executeScriptOptions)
+}
+
+type executeScriptOptions struct {
+	eventOptions
+	autoRemove bool
+	attrs      []g.Node
+}
+
+type executeScriptOption func(*executeScriptOptions)
+
+func (o executeScriptOption) applyExecuteScript(opts *executeScriptOptions) {
+	o(opts)
+}
+
+// WithAutoRemove sets whether the script element removes itself from the DOM after running. The default is true.
+func WithAutoRemove(autoRemove bool) ExecuteScriptOption {
+	return executeScriptOption(func(opts *executeScriptOptions) {
+		opts.autoRemove = autoRemove
+	})
+}
+
+// WithScriptAttrs adds attributes to the script element, such as `type="module"`.
+// Can be given multiple times.
+func WithScriptAttrs(attrs ...g.Node) ExecuteScriptOption {
+	return executeScriptOption(func(opts *executeScriptOptions) {
+		opts.attrs = append(opts.attrs, attrs...)
+	})
+}
+
+// EventOption configures options common to all events.
+type EventOption func(*eventOptions)
+
+func (o EventOption) applyPatchElements(opts *patchElementsOptions) {
+	o(&opts.eventOptions)
+}
+
+func (o EventOption) applyPatchSignals(opts *patchSignalsOptions) {
+	o(&opts.eventOptions)
+}
+
+func (o EventOption) applyExecuteScript(opts *executeScriptOptions) {
+	o(&opts.eventOptions)
+}
+
+type eventOptions struct {
+	id    string
+	retry time.Duration
+}
+
+// WithEventID sets the event ID, which the client sends back in the Last-Event-ID header when reconnecting.
+// Panics if the ID contains a newline.
+func WithEventID(id string) EventOption {
+	if strings.ContainsAny(id, "\r\n") {
+		panic(fmt.Sprintf("event ID must not contain newlines, but is: %q", id))
+	}
+	return func(opts *eventOptions) {
+		opts.id = id
+	}
+}
+
+// WithRetryDuration sets how long the client waits before reconnecting if the connection is lost.
+// Panics if the duration is negative.
+func WithRetryDuration(d time.Duration) EventOption {
+	if d < 0 {
+		panic(fmt.Sprintf("retry duration must not be negative, but is: %v", d))
+	}
+	return func(opts *eventOptions) {
+		opts.retry = d
+	}
+}
+
+func patchElementsEvent(node g.Node, opts []PatchElementsOption) (event, error) {
+	var o patchElementsOptions
+	for _, opt := range opts {
+		opt.applyPatchElements(&o)
+	}
+
+	var b strings.Builder
+	if node != nil {
+		if err := node.Render(&b); err != nil {
+			return event{}, fmt.Errorf("failed to render elements: %w", err)
+		}
+	}
+
+	return newPatchElementsEvent(o, b.String()), nil
+}
+
+func newPatchElementsEvent(o patchElementsOptions, elements string) event {
+	e := newEvent(EventTypePatchElements, o.eventOptions)
+	if o.selector != "" {
+		// CSS treats newlines as whitespace, but they would end the data line
+		e.addData("selector", strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(o.selector))
+	}
+	if o.mode != "" && o.mode != PatchModeOuter {
+		e.addData("mode", string(o.mode))
+	}
+	if o.viewTransition {
+		e.addData("useViewTransition", "true")
+	}
+	if elements != "" {
+		e.addLines("elements", elements)
+	}
+	return e
+}
+
+func patchSignalsEvent(signals any, opts []PatchSignalsOption) (event, error) {
+	var o patchSignalsOptions
+	for _, opt := range opts {
+		opt.applyPatchSignals(&o)
+	}
+
+	b, err := marshalSignals(signals)
+	if err != nil {
+		return event{}, err
+	}
+
+	e := newEvent(EventTypePatchSignals, o.eventOptions)
+	if o.onlyIfMissing {
+		e.addData("onlyIfMissing", "true")
+	}
+	e.addLines("signals", string(b))
+
+	return e, nil
+}
+
+func executeScriptEvent(script string, opts []ExecuteScriptOption) (event, error) {
+	if strings.Contains(strings.ToLower(script), "</script") {
+		return event{}, errors.New("script must not contain a closing </script> tag")
+	}
+
+	o := executeScriptOptions{autoRemove: true}
+	for _, opt := range opts {
+		opt.applyExecuteScript(&o)
+	}
+
+	var b strings.Builder
+	b.WriteString("<script")
+	if o.autoRemove {
+		b.WriteString(` data-effect="el.remove()"`)
+	}
+	for _, attr := range o.attrs {
+		if err := attr.Render(&b); err != nil {
+			return event{}, fmt.Errorf("failed to render script attributes: %w", err)
+		}
+	}
+	b.WriteString(">" + script + "</script>")
+
+	return newPatchElementsEvent(patchElementsOptions{
+		eventOptions: o.eventOptions,
+		selector:     "body",
+		mode:         PatchModeAppend,
+	}, b.String()), nil
+}
+
+// event is a single Server-Sent Event.
+type event struct {
+	typ   string
+	id    string
+	retry time.Duration
+	data  []string
+}
+
+func newEvent(typ string, opts eventOptions) event {
+	return event{
+		typ:   typ,
+		id:    opts.id,
+		retry: opts.retry,
+	}
+}
+
+// addData adds a single data line prefixed with the key.
+func (e *event) addData(key, value string) {
+	e.data = append(e.data, key+" "+value)
+}
+
+// addLines adds one data line prefixed with the key for each line in value,
+// since SSE data lines must not contain line breaks, which are CRLF, LF, or a lone CR.
+func (e *event) addLines(key, value string) {
+	value = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(value)
+	for _, line := range strings.Split(value, "\n") {
+		e.addData(key, line)
+	}
+}
+
+func (e event) writeTo(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("event: " + e.typ + "\n")
+	if e.id != "" {
+		b.WriteString("id: " + e.id + "\n")
+	}
+	if e.retry > 0 {
+		b.WriteString(fmt.Sprintf("retry: %v\n", e.retry.Milliseconds()))
+	}
+	for _, line := range e.data {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
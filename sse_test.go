@@ -0,0 +1,486 @@
+package datastar_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	g "maragu.dev/gomponents"
+	. "maragu.dev/gomponents/html"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+func TestNewSSE(t *testing.T) {
+	t.Run("should set headers and status code", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		_ = data.NewSSE(w, r)
+
+		assert.EqualString(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.EqualString(t, "no-cache", w.Header().Get("Cache-Control"))
+		assert.EqualString(t, "keep-alive", w.Header().Get("Connection"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status code %v but got %v", http.StatusOK, w.Code)
+		}
+		if !w.Flushed {
+			t.Fatal("expected response to be flushed")
+		}
+	})
+
+	t.Run("should not set the connection header for HTTP/2", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.ProtoMajor = 2
+
+		_ = data.NewSSE(w, r)
+
+		assert.EqualString(t, "", w.Header().Get("Connection"))
+	})
+}
+
+func TestSSE_PatchElements(t *testing.T) {
+	t.Run("should send a datastar-patch-elements event", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.PatchElements(Div(ID("foo"), g.Text("Hello")))
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-elements\n"+
+			"data: elements <div id=\"foo\">Hello</div>\n\n", w.Body.String())
+	})
+
+	t.Run("should split multiline elements into several data lines", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.PatchElements(g.Raw("<div id=\"foo\">\n<p>Hello</p>\r\n</div>"))
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-elements\n"+
+			"data: elements <div id=\"foo\">\n"+
+			"data: elements <p>Hello</p>\n"+
+			"data: elements </div>\n\n", w.Body.String())
+	})
+
+	t.Run("should split elements on lone carriage returns", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.PatchElements(Div(ID("foo"), g.Text("a\rb")))
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-elements\n"+
+			"data: elements <div id=\"foo\">a\n"+
+			"data: elements b</div>\n\n", w.Body.String())
+	})
+
+	t.Run("should send event ID and retry duration", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.PatchElements(Div(ID("foo")), data.WithEventID("123"), data.WithRetryDuration(2*time.Second))
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-elements\n"+
+			"id: 123\n"+
+			"retry: 2000\n"+
+			"data: elements <div id=\"foo\"></div>\n\n", w.Body.String())
+	})
+
+	t.Run("should send several events in order", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		assert.NoError(t, sse.PatchElements(Div(ID("foo"))))
+		assert.NoError(t, sse.PatchElements(Div(ID("bar"))))
+
+		assert.EqualString(t, "event: datastar-patch-elements\n"+
+			"data: elements <div id=\"foo\"></div>\n\n"+
+			"event: datastar-patch-elements\n"+
+			"data: elements <div id=\"bar\"></div>\n\n", w.Body.String())
+	})
+
+	t.Run("should send selector, mode, and view transition", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.PatchElements(Li(g.Text("New")), data.WithSelector("#list"), data.WithMode(data.PatchModeAppend), data.WithViewTransition())
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-elements\n"+
+			"data: selector #list\n"+
+			"data: mode append\n"+
+			"data: useViewTransition true\n"+
+			"data: elements <li>New</li>\n\n", w.Body.String())
+	})
+
+	t.Run("should not send the default outer mode", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.PatchElements(Div(ID("foo")), data.WithMode(data.PatchModeOuter))
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-elements\n"+
+			"data: elements <div id=\"foo\"></div>\n\n", w.Body.String())
+	})
+
+	t.Run("should send a multiline selector on one line", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.PatchElements(nil, data.WithSelector("#foo,\n#bar"), data.WithMode(data.PatchModeRemove))
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-elements\n"+
+			"data: selector #foo, #bar\n"+
+			"data: mode remove\n\n", w.Body.String())
+	})
+
+	t.Run("should return an error if the request context is canceled", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx, cancel := context.WithCancel(r.Context())
+		sse := data.NewSSE(w, r.WithContext(ctx))
+		cancel()
+
+		err := sse.PatchElements(Div(ID("foo")))
+		assert.Error(t, err)
+	})
+}
+
+func TestSSE_PatchSignals(t *testing.T) {
+	t.Run("should send a datastar-patch-signals event from a map", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.PatchSignals(map[string]any{"foo": 1, "bar": map[string]any{"baz": true}})
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-signals\n"+
+			"data: signals {\"bar\":{\"baz\":true},\"foo\":1}\n\n", w.Body.String())
+	})
+
+	t.Run("should send a datastar-patch-signals event from a struct", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.PatchSignals(struct {
+			Foo  int    `json:"foo"`
+			Name string `json:"name"`
+		}{Foo: 1, Name: "Datastar"})
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-signals\n"+
+			"data: signals {\"foo\":1,\"name\":\"Datastar\"}\n\n", w.Body.String())
+	})
+
+	t.Run("should send onlyIfMissing, event ID, and retry duration", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.PatchSignals(map[string]any{"foo": 1}, data.WithOnlyIfMissing(), data.WithEventID("123"), data.WithRetryDuration(500*time.Millisecond))
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-signals\n"+
+			"id: 123\n"+
+			"retry: 500\n"+
+			"data: onlyIfMissing true\n"+
+			"data: signals {\"foo\":1}\n\n", w.Body.String())
+	})
+
+	t.Run("should encode signals like the signals attribute", func(t *testing.T) {
+		w, sse := newSSE(t)
+		signals := map[string]any{"foo": "<b>", "bar": nil}
+
+		err := sse.PatchSignals(signals)
+		assert.NoError(t, err)
+
+		var b strings.Builder
+		_ = data.Signals(signals).Render(&b)
+		assert.EqualString(t, ` data-signals="{&#34;bar&#34;:null,&#34;foo&#34;:&#34;\u003cb\u003e&#34;}"`, b.String())
+		assert.EqualString(t, "event: datastar-patch-signals\n"+
+			"data: signals {\"bar\":null,\"foo\":\"\\u003cb\\u003e\"}\n\n", w.Body.String())
+	})
+
+	t.Run("should return an error if the signals cannot be marshalled", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.PatchSignals(map[string]any{"foo": func() {}})
+		assert.Error(t, err)
+		assert.EqualString(t, "", w.Body.String())
+	})
+}
+
+func TestSSE_RemoveElements(t *testing.T) {
+	t.Run("should send a remove event for the selector", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.RemoveElements("#foo", data.WithEventID("1"))
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-elements\n"+
+			"id: 1\n"+
+			"data: selector #foo\n"+
+			"data: mode remove\n\n", w.Body.String())
+	})
+
+	t.Run("should not let options override the selector and mode", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.RemoveElements("#foo", data.WithSelector("#bar"), data.WithMode(data.PatchModeInner))
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-elements\n"+
+			"data: selector #foo\n"+
+			"data: mode remove\n\n", w.Body.String())
+	})
+
+	t.Run("should return an error for an empty selector", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.RemoveElements("")
+		assert.Error(t, err)
+		assert.EqualString(t, "", w.Body.String())
+	})
+}
+
+func TestSSE_ExecuteScript(t *testing.T) {
+	t.Run("should append a self-removing script to the body", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.ExecuteScript("console.log('hi')")
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-elements\n"+
+			"data: selector body\n"+
+			"data: mode append\n"+
+			"data: elements <script data-effect=\"el.remove()\">console.log('hi')</script>\n\n", w.Body.String())
+	})
+
+	t.Run("should not auto-remove the script if disabled", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.ExecuteScript("console.log('hi')", data.WithAutoRemove(false))
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-elements\n"+
+			"data: selector body\n"+
+			"data: mode append\n"+
+			"data: elements <script>console.log('hi')</script>\n\n", w.Body.String())
+	})
+
+	t.Run("should add script attributes and event options", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.ExecuteScript("import foo from './foo.js'\nfoo()",
+			data.WithScriptAttrs(Type("module"), g.Attr("nonce", "abc")), data.WithEventID("1"))
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "event: datastar-patch-elements\n"+
+			"id: 1\n"+
+			"data: selector body\n"+
+			"data: mode append\n"+
+			"data: elements <script data-effect=\"el.remove()\" type=\"module\" nonce=\"abc\">import foo from './foo.js'\n"+
+			"data: elements foo()</script>\n\n", w.Body.String())
+	})
+
+	t.Run("should return an error for a closing script tag in the script", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.ExecuteScript("console.log('</SCRIPT >')")
+		assert.Error(t, err)
+		assert.EqualString(t, "script must not contain a closing </script> tag", err.Error())
+		assert.EqualString(t, "", w.Body.String())
+	})
+}
+
+func TestSSE_Redirect(t *testing.T) {
+	t.Run("should send a script that navigates to the URL", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.Redirect("/dashboard?tab=1")
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "setTimeout(() => window.location.href = '/dashboard?tab=1')", parseScript(t, w.Body.String()))
+	})
+
+	t.Run("should escape the URL", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.Redirect("/'</script>")
+		assert.NoError(t, err)
+
+		assert.EqualString(t, `setTimeout(() => window.location.href = '/\'\x3C/script>')`, parseScript(t, w.Body.String()))
+	})
+}
+
+func TestSSE_ConsoleLog(t *testing.T) {
+	t.Run("should send a script that logs the value", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.ConsoleLog(map[string]any{"foo": "bar\nbaz", "n": 1})
+		assert.NoError(t, err)
+
+		assert.EqualString(t, `console.log({foo: 'bar\nbaz', n: 1})`, parseScript(t, w.Body.String()))
+	})
+
+	t.Run("should return an error if the value can't be encoded", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.ConsoleLog(make(chan int))
+		assert.Error(t, err)
+		assert.EqualString(t, "", w.Body.String())
+	})
+}
+
+func TestSSE_DispatchCustomEvent(t *testing.T) {
+	t.Run("should dispatch the event on the document without a selector", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.DispatchCustomEvent("cart-updated", map[string]any{"count": 3}, "")
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "document.dispatchEvent(new CustomEvent('cart-updated', "+
+			"{bubbles: true, cancelable: true, composed: true, detail: {count: 3}}))", parseScript(t, w.Body.String()))
+	})
+
+	t.Run("should dispatch the event on all elements matching the selector", func(t *testing.T) {
+		w, sse := newSSE(t)
+
+		err := sse.DispatchCustomEvent("refresh", nil, "#list > li")
+		assert.NoError(t, err)
+
+		assert.EqualString(t, "document.querySelectorAll('#list > li').forEach((el) => el.dispatchEvent(new CustomEvent('refresh', "+
+			"{bubbles: true, cancelable: true, composed: true, detail: null})))", parseScript(t, w.Body.String()))
+	})
+
+	t.Run("should return an error for an empty event name", func(t *testing.T) {
+		_, sse := newSSE(t)
+
+		err := sse.DispatchCustomEvent("", nil, "")
+		assert.Error(t, err)
+	})
+
+	t.Run("should return an error if the detail can't be encoded", func(t *testing.T) {
+		_, sse := newSSE(t)
+
+		err := sse.DispatchCustomEvent("foo", func() {}, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestWithEventID(t *testing.T) {
+	t.Run("should panic on newlines", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic for newline in event ID")
+			}
+		}()
+		data.WithEventID("1\n2")
+	})
+}
+
+func TestWithRetryDuration(t *testing.T) {
+	t.Run("should panic on negative duration", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic for negative retry duration")
+			}
+		}()
+		data.WithRetryDuration(-1)
+	})
+}
+
+func TestWithMode(t *testing.T) {
+	t.Run("should panic on unknown mode", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic")
+			}
+		}()
+		data.WithMode("upsert")
+	})
+}
+
+func newSSE(t *testing.T) (*httptest.ResponseRecorder, *data.SSE) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	sse := data.NewSSE(w, r)
+	return w, sse
+}
+
+// parseScript parses a single patch elements event from the body and returns the contents of the script element in it.
+func parseScript(t *testing.T, body string) string {
+	t.Helper()
+
+	if !strings.HasPrefix(body, "event: datastar-patch-elements\n") || !strings.HasSuffix(body, "\n\n") {
+		t.Fatalf("expected a single datastar-patch-elements event, got %q", body)
+	}
+
+	var elements []string
+	for _, line := range strings.Split(strings.TrimSuffix(body, "\n\n"), "\n")[1:] {
+		if strings.HasPrefix(line, "data: elements ") {
+			elements = append(elements, strings.TrimPrefix(line, "data: elements "))
+		}
+	}
+
+	html := strings.Join(elements, "\n")
+	start := strings.Index(html, ">")
+	end := strings.LastIndex(html, "</script>")
+	if !strings.HasPrefix(html, "<script") || start < 0 || end < start {
+		t.Fatalf("expected a script element, got %q", html)
+	}
+	return html[start+1 : end]
+}
+
+func ExampleSSE_PatchElements() {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	sse := data.NewSSE(w, r)
+	_ = sse.PatchElements(Div(ID("foo"), g.Text("Hello")))
+
+	fmt.Print(w.Body.String())
+	// Output: event: datastar-patch-elements
+	// data: elements <div id="foo">Hello</div>
+}
+
+func ExampleSSE_ExecuteScript() {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	sse := data.NewSSE(w, r)
+	_ = sse.ExecuteScript("console.log('Hello')")
+
+	fmt.Print(w.Body.String())
+	// Output: event: datastar-patch-elements
+	// data: selector body
+	// data: mode append
+	// data: elements <script data-effect="el.remove()">console.log('Hello')</script>
+}
+
+func ExampleSSE_RemoveElements() {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	sse := data.NewSSE(w, r)
+	_ = sse.RemoveElements("#notification")
+
+	fmt.Print(w.Body.String())
+	// Output: event: datastar-patch-elements
+	// data: selector #notification
+	// data: mode remove
+}
+
+func ExampleSSE_PatchSignals() {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	sse := data.NewSSE(w, r)
+	_ = sse.PatchSignals(map[string]any{"foo": 1}, data.WithOnlyIfMissing())
+
+	fmt.Print(w.Body.String())
+	// Output: event: datastar-patch-signals
+	// data: onlyIfMissing true
+	// data: signals {"foo":1}
+}
@@ -0,0 +1,139 @@
+package datastar
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SignalNameError is returned by [ValidateSignalName] and [ValidateSignals] for invalid signal names.
+type SignalNameError struct {
+	Name   string
+	Reason string
+}
+
+func (e *SignalNameError) Error() string {
+	return fmt.Sprintf("invalid signal name %q: %v", e.Name, e.Reason)
+}
+
+// reservedSignalNames can't be used as signal names or path segments, since they clash with JavaScript object internals.
+var reservedSignalNames = map[string]bool{
+	"__proto__":   true,
+	"constructor": true,
+	"prototype":   true,
+}
+
+// ValidateSignalName checks that the name is a valid signal name, returning a [*SignalNameError] if not.
+// The name can be a dot-separated path for nested signals, such as "user.name".
+// Each segment of the path must:
+//
+//   - not be empty,
+//   - only contain letters, digits, and underscores, and not begin with a digit,
+//   - not contain a double underscore (__), due to its use as a modifier delimiter,
+//   - not be a reserved name (__proto__, constructor, prototype).
+//
+// All helpers taking signal names validate them and panic on invalid names:
+// [Bind], [BindKey], [Indicator], [Ref], [Signals], [SignalKey], [ComputedKey], [SignalsFrom], [ScopedSignals], [OrderedSignals.Set],
+// [Sig], [NewSignal], and [NewField].
+// Check names from untrusted sources with ValidateSignalName first.
+func ValidateSignalName(name string) error {
+	if name == "" {
+		return &SignalNameError{Name: name, Reason: "must not be empty"}
+	}
+	for _, segment := range strings.Split(name, ".") {
+		if reason := validateSignalNameSegment(segment); reason != "" {
+			return &SignalNameError{Name: name, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// ValidateSignals checks that all keys in the signals, including keys of nested maps, are valid signal names.
+// Keys must not contain dots, since nesting is expressed with nested maps. See [ValidateSignalName].
+func ValidateSignals(signals map[string]any) error {
+	return validateSignals(signals, "")
+}
+
+func validateSignals(signals map[string]any, path string) error {
+	// Sort the keys, so the same error is returned every time
+	keys := make([]string, 0, len(signals))
+	for key := range signals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := joinPath(path, key)
+		if reason := validateSignalNameSegment(key); reason != "" {
+			return &SignalNameError{Name: name, Reason: reason}
+		}
+		if nested, ok := signals[key].(map[string]any); ok {
+			if err := validateSignals(nested, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateSignalNameSegment returns the reason the segment is invalid, or the empty string if it is valid.
+func validateSignalNameSegment(segment string) string {
+	switch {
+	case segment == "":
+		return "must not have empty path segments"
+	case strings.Contains(segment, "__"):
+		return "must not contain a double underscore (__)"
+	case reservedSignalNames[segment]:
+		return fmt.Sprintf("%q is reserved", segment)
+	}
+
+	for i, r := range segment {
+		if i == 0 && unicode.IsDigit(r) {
+			return "must not begin with a digit"
+		}
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return fmt.Sprintf("must only contain letters, digits, and underscores, but contains %q", r)
+		}
+	}
+	return ""
+}
+
+func mustValidateSignalName(name string) {
+	if err := ValidateSignalName(name); err != nil {
+		panic(err.Error())
+	}
+}
+
+func mustValidateSignals(signals map[string]any) {
+	if err := ValidateSignals(signals); err != nil {
+		panic(err.Error())
+	}
+}
+
+// mustValidateKey panics if the key can't be used in the keyed form of an attribute, such as data-class:key.
+func mustValidateKey(key string) {
+	switch {
+	case key == "":
+		panic("key must not be empty")
+	case strings.Contains(key, "__"):
+		panic(fmt.Sprintf("key %q must not contain a double underscore (__)", key))
+	}
+	for _, r := range key {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(`"'<>/=`+"`", r) {
+			panic(fmt.Sprintf("key %q must not contain %q", key, r))
+		}
+	}
+}
+
+// mustValidateSignalKey panics if the key is not a valid attribute key, or not a valid signal name.
+// Hyphens are allowed, since Datastar converts keys from kebab case to signal names.
+func mustValidateSignalKey(key string) {
+	mustValidateKey(key)
+	var err *SignalNameError
+	if errors.As(ValidateSignalName(strings.ReplaceAll(key, "-", "_")), &err) {
+		err.Name = key
+		panic(err.Error())
+	}
+}
@@ -0,0 +1,102 @@
+package datastar_test
+
+import (
+	"errors"
+	"testing"
+
+	. "maragu.dev/gomponents/html"
+
+	data "maragu.dev/gomponents-datastar"
+	"maragu.dev/gomponents-datastar/internal/assert"
+)
+
+func TestValidateSignalName(t *testing.T) {
+	valid := []string{"foo", "fooBar", "foo_bar", "_foo", "foo1", "user.name", "_ui.open", "æøå"}
+	for _, name := range valid {
+		t.Run("should accept "+name, func(t *testing.T) {
+			assert.NoError(t, data.ValidateSignalName(name))
+		})
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "", expected: `invalid signal name "": must not be empty`},
+		{name: "foo__bar", expected: `invalid signal name "foo__bar": must not contain a double underscore (__)`},
+		{name: "__foo", expected: `invalid signal name "__foo": must not contain a double underscore (__)`},
+		{name: "user.__foo", expected: `invalid signal name "user.__foo": must not contain a double underscore (__)`},
+		{name: "user..name", expected: `invalid signal name "user..name": must not have empty path segments`},
+		{name: ".foo", expected: `invalid signal name ".foo": must not have empty path segments`},
+		{name: "foo-bar", expected: `invalid signal name "foo-bar": must only contain letters, digits, and underscores, but contains '-'`},
+		{name: "foo bar", expected: `invalid signal name "foo bar": must only contain letters, digits, and underscores, but contains ' '`},
+		{name: "$foo", expected: `invalid signal name "$foo": must only contain letters, digits, and underscores, but contains '$'`},
+		{name: "1foo", expected: `invalid signal name "1foo": must not begin with a digit`},
+		{name: "constructor", expected: `invalid signal name "constructor": "constructor" is reserved`},
+		{name: "user.prototype", expected: `invalid signal name "user.prototype": "prototype" is reserved`},
+	}
+	for _, test := range tests {
+		t.Run("should reject "+test.name, func(t *testing.T) {
+			err := data.ValidateSignalName(test.name)
+			assert.Error(t, err)
+			assert.EqualString(t, test.expected, err.Error())
+
+			var nameErr *data.SignalNameError
+			if !errors.As(err, &nameErr) {
+				t.Fatalf("expected SignalNameError but got %T", err)
+			}
+			assert.EqualString(t, test.name, nameErr.Name)
+		})
+	}
+}
+
+func TestValidateSignals(t *testing.T) {
+	t.Run("should accept valid nested signals", func(t *testing.T) {
+		assert.NoError(t, data.ValidateSignals(map[string]any{"foo": 1, "user": map[string]any{"name": "", "_open": false}}))
+	})
+
+	t.Run("should reject invalid nested names with their full path", func(t *testing.T) {
+		err := data.ValidateSignals(map[string]any{"foo": 1, "user": map[string]any{"first__name": ""}})
+		assert.Error(t, err)
+		assert.EqualString(t, `invalid signal name "user.first__name": must not contain a double underscore (__)`, err.Error())
+	})
+
+	t.Run("should reject dots in keys", func(t *testing.T) {
+		err := data.ValidateSignals(map[string]any{"user.name": ""})
+		assert.Error(t, err)
+	})
+}
+
+func TestSignalNameValidationInHelpers(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{name: "Bind", fn: func() { data.Bind("foo__bar") }},
+		{name: "Indicator", fn: func() { data.Indicator("foo bar") }},
+		{name: "Ref", fn: func() { data.Ref("") }},
+		{name: "Signals", fn: func() { data.Signals(map[string]any{"foo": map[string]any{"__bar": 1}}) }},
+		{name: "SignalsFrom", fn: func() {
+			data.SignalsFrom(struct {
+				Foo string `json:"foo-bar"`
+			}{})
+		}},
+		{name: "NewSignal", fn: func() { data.NewSignal("constructor", 0) }},
+		{name: "Sig", fn: func() { data.Sig("user..name") }},
+	}
+
+	for _, test := range tests {
+		t.Run("should panic on invalid names in "+test.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("expected panic for invalid signal name")
+				}
+			}()
+			test.fn()
+		})
+	}
+
+	t.Run("should accept nested paths in Bind", func(t *testing.T) {
+		assert.Equal(t, `<input data-bind="user.name">`, Input(data.Bind("user.name")))
+	})
+}